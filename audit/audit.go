@@ -0,0 +1,216 @@
+package audit
+
+import (
+	"bufio"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/securityfirst/tent/logging"
+)
+
+// Outcome of a successful operation
+const OutcomeOK = "ok"
+
+var ErrChain = errors.New("audit chain broken")
+
+// Entry is a record of the log, chained to the previous one by its hash.
+// User is the ID of the user, Login only describes it; From is the source of a move.
+type Entry struct {
+	Seq     int       `json:"seq"`
+	Time    time.Time `json:"time"`
+	Action  string    `json:"action"`
+	User    string    `json:"user"`
+	Login   string    `json:"login,omitempty"`
+	IP      string    `json:"ip,omitempty"`
+	Path    string    `json:"path"`
+	From    string    `json:"from,omitempty"`
+	Locale  string    `json:"locale,omitempty"`
+	OldHash string    `json:"old_hash,omitempty"`
+	NewHash string    `json:"new_hash,omitempty"`
+	Outcome string    `json:"outcome"`
+	Prev    string    `json:"prev"`
+	Hash    string    `json:"hash"`
+}
+
+// sum returns the hash of the entry, an HMAC if there is a key
+func (e *Entry) sum(key []byte) string {
+	v := *e
+	v.Hash = ""
+	b, _ := json.Marshal(v)
+	if len(key) == 0 {
+		h := sha256.Sum256(b)
+		return hex.EncodeToString(h[:])
+	}
+	h := hmac.New(sha256.New, key)
+	h.Write(b)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// Query filters the entries, zero values match everything
+type Query struct {
+	User   string
+	Action string
+	Path   string
+	Locale string
+	Since  time.Time
+	Until  time.Time
+	Limit  int
+}
+
+func (q *Query) match(e *Entry) bool {
+	switch {
+	case q.User != "" && !strings.EqualFold(q.User, e.User) && !strings.EqualFold(q.User, e.Login),
+		q.Action != "" && q.Action != e.Action,
+		q.Path != "" && !strings.HasPrefix(e.Path, q.Path) && (e.From == "" || !strings.HasPrefix(e.From, q.Path)),
+		q.Locale != "" && q.Locale != e.Locale,
+		!q.Since.IsZero() && e.Time.Before(q.Since),
+		!q.Until.IsZero() && e.Time.After(q.Until):
+		return false
+	}
+	return true
+}
+
+// Log is an append-only, hash-chained log stored as JSON lines. Without a key
+// the chain only detects accidental damage, as anyone with write access to
+// the file can recompute it: with a key the hashes are HMACs.
+type Log struct {
+	sync.Mutex
+	path   string
+	ipMode string
+	key    []byte
+	file   *os.File
+	last   Entry
+}
+
+// Open opens the log at path verifying its chain, the key is optional
+func Open(path, ipMode, key string) (*Log, error) {
+	l := Log{path: path, ipMode: ipMode, key: []byte(key)}
+	if err := l.Verify(); err != nil {
+		return nil, err
+	}
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0600)
+	if err != nil {
+		return nil, err
+	}
+	l.file = f
+	return &l, nil
+}
+
+// Record appends the entry to the log, setting sequence, time and hashes
+func (l *Log) Record(e Entry) error {
+	l.Lock()
+	defer l.Unlock()
+	e.Seq, e.Prev = l.last.Seq+1, l.last.Hash
+	if e.Time.IsZero() {
+		e.Time = time.Now().UTC()
+	}
+	e.IP = logging.RedactIP(e.IP, l.ipMode)
+	e.Hash = e.sum(l.key)
+	b, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	if _, err := l.file.Write(append(b, '\n')); err != nil {
+		return err
+	}
+	if err := l.file.Sync(); err != nil {
+		return err
+	}
+	l.last = e
+	return nil
+}
+
+// Verify checks the whole chain
+func (l *Log) Verify() error {
+	l.Lock()
+	defer l.Unlock()
+	var last Entry
+	err := l.scan(func(e *Entry) error {
+		if e.Seq != last.Seq+1 || e.Prev != last.Hash || e.Hash != e.sum(l.key) {
+			return fmt.Errorf("%s at %d", ErrChain, last.Seq+1)
+		}
+		last = *e
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	l.last = last
+	return nil
+}
+
+// Query returns the entries matching the query, latest first
+func (l *Log) Query(q Query) ([]Entry, error) {
+	var list []Entry
+	err := l.scan(func(e *Entry) error {
+		if q.match(e) {
+			list = append(list, *e)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	for i, j := 0, len(list)-1; i < j; i, j = i+1, j-1 {
+		list[i], list[j] = list[j], list[i]
+	}
+	if q.Limit > 0 && len(list) > q.Limit {
+		list = list[:q.Limit]
+	}
+	return list, nil
+}
+
+// Export writes the entries matching the query as JSON lines, in log order
+func (l *Log) Export(w io.Writer, q Query) error {
+	var n int
+	enc := json.NewEncoder(w)
+	return l.scan(func(e *Entry) error {
+		if !q.match(e) || q.Limit > 0 && n >= q.Limit {
+			return nil
+		}
+		n++
+		return enc.Encode(e)
+	})
+}
+
+// Close closes the underlying file
+func (l *Log) Close() error {
+	l.Lock()
+	defer l.Unlock()
+	return l.file.Close()
+}
+
+func (l *Log) scan(fn func(*Entry) error) error {
+	f, err := os.Open(l.path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+	defer f.Close()
+	s := bufio.NewScanner(f)
+	s.Buffer(nil, 1<<20)
+	for s.Scan() {
+		if len(s.Bytes()) == 0 {
+			continue
+		}
+		var e Entry
+		if err := json.Unmarshal(s.Bytes(), &e); err != nil {
+			return fmt.Errorf("%s: %s", ErrChain, err)
+		}
+		if err := fn(&e); err != nil {
+			return err
+		}
+	}
+	return s.Err()
+}
@@ -0,0 +1,107 @@
+package audit
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/securityfirst/tent/logging"
+)
+
+func TestChain(t *testing.T) {
+	dir, err := ioutil.TempDir("", "audit")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "audit.log")
+
+	l, err := Open(path, logging.IPTruncate, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, e := range []Entry{
+		{Action: "create", User: "a", IP: "10.1.2.3", Path: "contents_en/cat/.metadata.md", Locale: "en", Outcome: OutcomeOK},
+		{Action: "update", User: "b", IP: "2001:db8:1:2::1", Path: "contents_en/cat/.metadata.md", Locale: "en", Outcome: OutcomeOK},
+		{Action: "delete", User: "a", Path: "forms_it/form.md", Locale: "it", Outcome: "not found"},
+		{Action: "move", User: "github:1", Login: "c", Path: "contents_it/b/.metadata.md", From: "contents_it/a/.metadata.md", Locale: "it", Outcome: OutcomeOK},
+	} {
+		if err := l.Record(e); err != nil {
+			t.Fatal(err)
+		}
+	}
+	l.Close()
+
+	l, err = Open(path, logging.IPTruncate, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	list, err := l.Query(Query{User: "a"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(list) != 2 || list[0].Seq != 3 || list[1].IP != "10.1.2.0" {
+		t.Errorf("unexpected query result %+v", list)
+	}
+	if list, _ := l.Query(Query{User: "C"}); len(list) != 1 || list[0].User != "github:1" {
+		t.Errorf("unexpected query result %+v", list)
+	}
+	if list, _ := l.Query(Query{Path: "contents_it/a/"}); len(list) != 1 || list[0].Action != "move" {
+		t.Errorf("unexpected query result %+v", list)
+	}
+	if list, _ := l.Query(Query{Path: "contents_en/"}); len(list) != 2 || list[0].IP != "2001:db8:1::" {
+		t.Errorf("unexpected query result %+v", list)
+	}
+	var b bytes.Buffer
+	if err := l.Export(&b, Query{Locale: "en", Limit: 1}); err != nil {
+		t.Fatal(err)
+	}
+	if n := strings.Count(b.String(), "\n"); n != 1 {
+		t.Errorf("expected 1 line, got %d", n)
+	}
+	l.Close()
+
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data = bytes.Replace(data, []byte(`"user":"b"`), []byte(`"user":"c"`), 1)
+	if err := ioutil.WriteFile(path, data, 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Open(path, logging.IPTruncate, ""); err == nil {
+		t.Error("expected broken chain")
+	}
+}
+
+func TestChainKey(t *testing.T) {
+	dir, err := ioutil.TempDir("", "audit")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "audit.log")
+
+	l, err := Open(path, logging.IPFull, "secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := l.Record(Entry{Action: "create", User: "a", Path: "forms_en/form.md", Outcome: OutcomeOK}); err != nil {
+		t.Fatal(err)
+	}
+	l.Close()
+	if _, err := Open(path, logging.IPFull, "other"); err == nil {
+		t.Error("expected broken chain with another key")
+	}
+	if _, err := Open(path, logging.IPFull, ""); err == nil {
+		t.Error("expected broken chain without key")
+	}
+	if l, err := Open(path, logging.IPFull, "secret"); err != nil {
+		t.Error(err)
+	} else {
+		l.Close()
+	}
+}
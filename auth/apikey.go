@@ -0,0 +1,253 @@
+package auth
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const keyPrefix = "tent_"
+
+// API key scopes
+const (
+	ScopeAll    = "*"
+	ScopeCreate = "create"
+	ScopeUpdate = "update"
+	ScopeDelete = "delete"
+)
+
+var (
+	ErrKey      = errors.New("invalid api key")
+	ErrKeyScope = errors.New("api key scope not allowed")
+	ErrScope    = errors.New("unknown api key scope")
+)
+
+// APIKey is a key used by automations, only the hash of the secret is stored
+type APIKey struct {
+	ID      string    `json:"id"`
+	Name    string    `json:"name"`
+	Hash    string    `json:"hash"`
+	Scopes  []string  `json:"scopes"`
+	Created time.Time `json:"created"`
+	Expires time.Time `json:"expires"`
+}
+
+// Expired tells if the key is expired, keys with no expiry never are
+func (k *APIKey) Expired() bool {
+	return !k.Expires.IsZero() && time.Now().After(k.Expires)
+}
+
+// Allows checks if the key has the scope for the http method
+func (k *APIKey) Allows(method string) bool {
+	var scope string
+	switch method {
+	case http.MethodPost:
+		scope = ScopeCreate
+	case http.MethodPut:
+		scope = ScopeUpdate
+	case http.MethodDelete:
+		scope = ScopeDelete
+	default:
+		return true
+	}
+	for _, s := range k.Scopes {
+		if s == scope || s == ScopeAll {
+			return true
+		}
+	}
+	return false
+}
+
+// ValidScope tells if the scope is one of the known ones
+func ValidScope(scope string) bool {
+	switch scope {
+	case ScopeAll, ScopeCreate, ScopeUpdate, ScopeDelete:
+		return true
+	}
+	return false
+}
+
+// IsAPIKey tells if the token looks like an API key
+func IsAPIKey(token string) bool { return strings.HasPrefix(token, keyPrefix) }
+
+// KeyStore keeps API keys in a JSON file, reloading it when it changes
+type KeyStore struct {
+	sync.RWMutex
+	path    string
+	keys    map[string]*APIKey
+	modTime time.Time
+	size    int64
+}
+
+// OpenKeyStore loads the keys from path, a missing file is an empty store
+func OpenKeyStore(path string) (*KeyStore, error) {
+	k := KeyStore{path: path, keys: make(map[string]*APIKey)}
+	if err := k.load(); err != nil {
+		return nil, err
+	}
+	return &k, nil
+}
+
+// load reads the file if it changed since the last time
+func (k *KeyStore) load() error {
+	info, err := os.Stat(k.path)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			return err
+		}
+		k.keys, k.modTime, k.size = make(map[string]*APIKey), time.Time{}, 0
+		return nil
+	}
+	if info.ModTime().Equal(k.modTime) && info.Size() == k.size {
+		return nil
+	}
+	f, err := os.Open(k.path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	var list []*APIKey
+	if err := json.NewDecoder(f).Decode(&list); err != nil {
+		return err
+	}
+	keys := make(map[string]*APIKey, len(list))
+	for _, v := range list {
+		keys[v.ID] = v
+	}
+	k.keys, k.modTime, k.size = keys, info.ModTime(), info.Size()
+	return nil
+}
+
+// reload reads the file if it changed, keeping the current keys on errors
+func (k *KeyStore) reload() error {
+	k.Lock()
+	defer k.Unlock()
+	return k.load()
+}
+
+// Create adds a new key and returns its secret, which is not stored
+func (k *KeyStore) Create(name string, scopes []string, expires time.Time) (string, *APIKey, error) {
+	for _, s := range scopes {
+		if !ValidScope(s) {
+			return "", nil, ErrScope
+		}
+	}
+	var (
+		id     = randomString(6)
+		secret = randomString(24)
+		key    = APIKey{
+			ID:      id,
+			Name:    name,
+			Hash:    hashSecret(secret),
+			Scopes:  scopes,
+			Created: time.Now(),
+			Expires: expires,
+		}
+	)
+	k.Lock()
+	defer k.Unlock()
+	if err := k.load(); err != nil {
+		return "", nil, err
+	}
+	k.keys[id] = &key
+	if err := k.save(); err != nil {
+		delete(k.keys, id)
+		return "", nil, err
+	}
+	return keyPrefix + id + "." + secret, &key, nil
+}
+
+// Revoke removes a key
+func (k *KeyStore) Revoke(id string) error {
+	k.Lock()
+	defer k.Unlock()
+	if err := k.load(); err != nil {
+		return err
+	}
+	key, ok := k.keys[id]
+	if !ok {
+		return ErrKey
+	}
+	delete(k.keys, id)
+	if err := k.save(); err != nil {
+		k.keys[id] = key
+		return err
+	}
+	return nil
+}
+
+// List returns all the keys sorted by creation
+func (k *KeyStore) List() []APIKey {
+	if err := k.reload(); err != nil {
+		log.Printf("API keys reload failed: %s", err)
+	}
+	k.RLock()
+	defer k.RUnlock()
+	var list = make([]APIKey, 0, len(k.keys))
+	for _, v := range k.keys {
+		list = append(list, *v)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Created.Before(list[j].Created) })
+	return list
+}
+
+// Check verifies a key and returns it if valid
+func (k *KeyStore) Check(token string) (*APIKey, error) {
+	parts := strings.SplitN(strings.TrimPrefix(token, keyPrefix), ".", 2)
+	if !IsAPIKey(token) || len(parts) != 2 {
+		return nil, ErrKey
+	}
+	if err := k.reload(); err != nil {
+		log.Printf("API keys reload failed: %s", err)
+		return nil, ErrKey
+	}
+	k.RLock()
+	key, ok := k.keys[parts[0]]
+	k.RUnlock()
+	if !ok || !equalString(key.Hash, hashSecret(parts[1])) || key.Expired() {
+		return nil, ErrKey
+	}
+	return key, nil
+}
+
+func (k *KeyStore) save() error {
+	var keys = make([]*APIKey, 0, len(k.keys))
+	for _, v := range k.keys {
+		keys = append(keys, v)
+	}
+	sort.Slice(keys, func(i, j int) bool { return keys[i].Created.Before(keys[j].Created) })
+	tmp := k.path + ".tmp"
+	f, err := os.OpenFile(tmp, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
+	if err != nil {
+		return err
+	}
+	e := json.NewEncoder(f)
+	e.SetIndent("", "\t")
+	if err := e.Encode(keys); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, k.path); err != nil {
+		return err
+	}
+	if info, err := os.Stat(k.path); err == nil {
+		k.modTime, k.size = info.ModTime(), info.Size()
+	}
+	return nil
+}
+
+func hashSecret(s string) string {
+	sum := sha256.Sum256([]byte(s))
+	return hex.EncodeToString(sum[:])
+}
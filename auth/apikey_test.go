@@ -0,0 +1,69 @@
+package auth
+
+import (
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestKeyStore(t *testing.T) {
+	dir, err := os.MkdirTemp("", "keys")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "keys.json")
+	k, err := OpenKeyStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := k.Create("bad", []string{"publish"}, time.Time{}); err != ErrScope {
+		t.Errorf("expected %v, got %v", ErrScope, err)
+	}
+	token, key, err := k.Create("ci", []string{ScopeUpdate}, time.Time{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !IsAPIKey(token) || !key.Allows(http.MethodPut) || key.Allows(http.MethodDelete) {
+		t.Errorf("unexpected key %s %+v", token, key)
+	}
+	if v, err := k.Check(token); err != nil || v.ID != key.ID {
+		t.Errorf("unexpected check %v: %v", v, err)
+	}
+	for _, v := range []string{token + "x", "tent_" + key.ID, "other"} {
+		if _, err := k.Check(v); err != ErrKey {
+			t.Errorf("%s: expected %v, got %v", v, ErrKey, err)
+		}
+	}
+	expired, _, err := k.Create("old", nil, time.Now().Add(-time.Second))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := k.Check(expired); err != ErrKey {
+		t.Errorf("expected expired key, got %v", err)
+	}
+	if l := k.List(); len(l) != 2 {
+		t.Errorf("expected 2 keys, got %v", l)
+	}
+	// a revocation by another process is seen by the running store
+	other, err := OpenKeyStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := other.Revoke(key.ID); err != nil {
+		t.Fatal(err)
+	}
+	if err := other.Revoke(key.ID); err != ErrKey {
+		t.Errorf("expected %v, got %v", ErrKey, err)
+	}
+	future := time.Now().Add(time.Minute)
+	os.Chtimes(path, future, future)
+	if _, err := k.Check(token); err != ErrKey {
+		t.Errorf("expected revoked key, got %v", err)
+	}
+	if l := k.List(); len(l) != 1 {
+		t.Errorf("expected 1 key, got %v", l)
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"net/http"
 	"path"
 	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/go-github/github"
@@ -15,25 +16,57 @@ import (
 )
 
 const (
-	sessionName = "github-auth"
-	githubUser  = "github-email"
+	sessionName     = "github-auth"
+	sessionDuration = 614880 * time.Second
+	csrfHeader      = "X-CSRF-Token"
 )
 
 // NewEngine creates a new Engine using and adds the handle for authentication
-func NewEngine(conf Config, root *gin.RouterGroup) *Engine {
+func NewEngine(conf Config, root *gin.RouterGroup) (*Engine, error) {
 	var e = Engine{
 		config: conf.OAuth(root),
 		cache:  make(map[string]models.User),
-		state:  conf.State,
+		cookie: conf.Cookie,
+		bot:    conf.Bot,
+		roles:  conf.Roles,
 	}
-	conf.Login.Redirect = e.config.AuthCodeURL(e.state, oauth2.AccessTypeOnline)
+	if conf.OIDC.Issuer != "" {
+		e.oidc = newOIDCProvider(conf.OIDC)
+	}
+	if conf.APIKeys != "" {
+		keys, err := OpenKeyStore(conf.APIKeys)
+		if err != nil {
+			return nil, fmt.Errorf("API keys: %s", err)
+		}
+		e.keys = keys
+	}
+	e.sessions = newSessionStore(conf.Cookie.Secret, conf.Cookie.duration())
 	conf.Callback.Redirect = path.Clean(root.BasePath() + conf.Callback.Redirect)
 	conf.Logout.Redirect = path.Clean(root.BasePath() + conf.Logout.Redirect)
 	root.GET(conf.Login.Endpoint, func(c *gin.Context) {
-		c.Redirect(http.StatusTemporaryRedirect, conf.Login.Redirect)
+		redirect := c.Query("redirect")
+		if !validRedirect(redirect, conf.Redirects) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid redirect"})
+			return
+		}
+		config, err := e.oauth()
+		if err != nil {
+			log.Printf("Login failed: %s", err)
+			c.JSON(http.StatusBadGateway, gin.H{"error": err.Error()})
+			return
+		}
+		flow := newLoginFlow(conf.PKCE, redirect)
+		if e.oidc != nil {
+			flow.Nonce = randomString(16)
+		}
+		http.SetCookie(c.Writer, e.cookie.flowCookie(e.sessions, flow))
+		c.Redirect(http.StatusTemporaryRedirect, config.AuthCodeURL(flow.State, flow.options()...))
 	})
 	root.GET(conf.Logout.Endpoint, func(c *gin.Context) {
-		c.SetCookie(githubUser, "", -1, "/", "", false, false)
+		if id, err := c.Cookie(e.cookie.name()); err == nil {
+			e.sessions.Delete(id)
+		}
+		http.SetCookie(c.Writer, e.cookie.cookie("", -1))
 		c.Redirect(http.StatusTemporaryRedirect, conf.Logout.Redirect)
 	})
 	root.GET(conf.Callback.Endpoint, func(c *gin.Context) {
@@ -42,32 +75,73 @@ func NewEngine(conf Config, root *gin.RouterGroup) *Engine {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": errString})
 			return
 		}
-		state := c.Query("state")
-		if state != conf.State {
-			log.Printf("Invalid oauth state, expected %q, got %q", conf.State, state)
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "invalid oauth state"})
+		flow, err := e.cookie.readFlow(e.sessions, c.Request, c.Query("state"))
+		http.SetCookie(c.Writer, e.cookie.flowCookie(e.sessions, nil))
+		if err != nil {
+			log.Printf("Invalid oauth state: %s", err)
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		config, err := e.oauth()
+		if err != nil {
+			log.Printf("Callback failed: %s", err)
+			c.JSON(http.StatusBadGateway, gin.H{"error": err.Error()})
 			return
 		}
 		code := c.Query("code")
-		token, err := e.config.Exchange(oauth2.NoContext, code)
+		token, err := config.Exchange(oauth2.NoContext, code, flow.exchangeOptions()...)
 		if err != nil || !token.Valid() {
 			log.Printf("Cannot get Token: %s", err)
 			c.Redirect(http.StatusTemporaryRedirect, "/")
 			return
 		}
-		c.SetCookie(githubUser, token.AccessToken, 614880, "/", "", false, false)
-		c.JSON(200, token)
+		var id string
+		var sess *session
+		if e.oidc != nil {
+			user, err := e.oidcUser(token, flow.Nonce)
+			if err != nil {
+				log.Printf("Cannot get User: %s", err)
+				c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+				return
+			}
+			id, sess = e.sessions.Create("", &user)
+		} else {
+			id, sess = e.sessions.Create(token.AccessToken, nil)
+		}
+		http.SetCookie(c.Writer, e.cookie.cookie(id, int(e.cookie.duration().Seconds())))
+		if flow.Redirect != "" {
+			c.Redirect(http.StatusFound, flow.Redirect)
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"csrf_token": sess.CSRF, "expires": sess.Expires})
 	})
-	return &e
+	if conf.Session.Endpoint != "" {
+		root.GET(conf.Session.Endpoint, e.EnsureUser, func(c *gin.Context) {
+			sess, _ := e.sessions.Get(c.GetString("session"))
+			if sess == nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": ErrSession.Error()})
+				return
+			}
+			c.JSON(http.StatusOK, gin.H{"csrf_token": sess.CSRF, "expires": sess.Expires, "user": c.MustGet("user")})
+		})
+	}
+	return &e, nil
 }
 
 // Engine is e struct that eases Github OAuth and resource handling
 type Engine struct {
-	config *oauth2.Config
-	state  string
-	cache  map[string]models.User
+	config   *oauth2.Config
+	cookie   CookieConf
+	sessions *sessionStore
+	oidc     *oidcProvider
+	keys     *KeyStore
+	bot      BotConf
+	roles    map[string][]string
+	cache    map[string]models.User
 }
 
+// EnsureUser authenticates the request using a Bearer token or the session cookie.
+// Requests authenticated by cookie that change data require the CSRF token header.
 func (e *Engine) EnsureUser(c *gin.Context) {
 	var token string
 	if auth := c.Request.Header.Get("Authorization"); auth != "" {
@@ -78,14 +152,37 @@ func (e *Engine) EnsureUser(c *gin.Context) {
 			return
 		}
 		token = parts[1]
+		if IsAPIKey(token) {
+			e.ensureKey(c, token)
+			return
+		}
 	} else {
-		cookie, err := c.Cookie(githubUser)
+		cookie, err := c.Cookie(e.cookie.name())
 		if err != nil && err != http.ErrNoCookie {
 			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid cookie"})
 			c.Abort()
 			return
 		}
-		token = cookie
+		if cookie != "" {
+			sess, err := e.sessions.Get(cookie)
+			if err != nil {
+				c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+				c.Abort()
+				return
+			}
+			if !isSafeMethod(c.Request.Method) && !equalString(c.GetHeader(csrfHeader), sess.CSRF) {
+				c.JSON(http.StatusForbidden, gin.H{"error": "invalid csrf token"})
+				c.Abort()
+				return
+			}
+			c.Header(csrfHeader, sess.CSRF)
+			c.Set("session", cookie)
+			if sess.User != nil {
+				e.ensureSessionUser(c, *sess.User)
+				return
+			}
+			token = sess.Token
+		}
 	}
 	if token == "" {
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "access denied"})
@@ -101,6 +198,74 @@ func (e *Engine) EnsureUser(c *gin.Context) {
 	c.Set("user", e.cache[token])
 }
 
+// OptionalUser authenticates the request only if it has credentials
+func (e *Engine) OptionalUser(c *gin.Context) {
+	if c.Request.Header.Get("Authorization") == "" {
+		if cookie, _ := c.Cookie(e.cookie.name()); cookie == "" {
+			return
+		}
+	}
+	e.EnsureUser(c)
+}
+
+// oauth returns the configuration of the login provider
+func (e *Engine) oauth() (*oauth2.Config, error) {
+	if e.oidc != nil {
+		return e.oidc.OAuth(e.config)
+	}
+	return e.config, nil
+}
+
+// oidcUser verifies the ID token received with the access token
+func (e *Engine) oidcUser(token *oauth2.Token, nonce string) (models.User, error) {
+	raw, _ := token.Extra("id_token").(string)
+	if raw == "" {
+		return models.User{}, ErrIDToken
+	}
+	claims, err := e.oidc.Verify(raw, e.config.ClientID, nonce)
+	if err != nil {
+		return models.User{}, err
+	}
+	return e.oidc.User(claims)
+}
+
+// ensureSessionUser authenticates users without a GitHub token, using the bot token
+// for commits and the user as author
+func (e *Engine) ensureSessionUser(c *gin.Context, u models.User) {
+	if e.bot.Token == "" {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "deploy token not configured"})
+		c.Abort()
+		return
+	}
+	c.Set("token", e.bot.Token)
+	c.Set("user", u)
+}
+
+// ensureKey authenticates an API key, using the bot identity and token
+func (e *Engine) ensureKey(c *gin.Context, token string) {
+	if e.keys == nil || e.bot.Token == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "api keys not enabled"})
+		c.Abort()
+		return
+	}
+	key, err := e.keys.Check(token)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		c.Abort()
+		return
+	}
+	if !key.Allows(c.Request.Method) {
+		c.JSON(http.StatusForbidden, gin.H{"error": ErrKeyScope.Error()})
+		c.Abort()
+		return
+	}
+	u := e.bot.User()
+	u.ID = ProviderKey + ":" + key.ID
+	c.Set("key", key.ID)
+	c.Set("token", e.bot.Token)
+	c.Set("user", u)
+}
+
 func (e *Engine) fetchUser(token string) error {
 	if _, ok := e.cache[token]; ok {
 		return nil
@@ -115,6 +280,19 @@ func (e *Engine) fetchUser(token string) error {
 	} else {
 		email = *u.Login + "@tent.org"
 	}
-	e.cache[token] = models.User{Name: *u.Name, Login: *u.Login, Email: email}
+	e.cache[token] = models.User{
+		ID:    ProviderGitHub + ":" + strings.ToLower(*u.Login),
+		Name:  *u.Name,
+		Login: *u.Login,
+		Email: email,
+	}
 	return nil
 }
+
+func isSafeMethod(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return true
+	}
+	return false
+}
@@ -2,9 +2,13 @@ package auth
 
 import (
 	"fmt"
+	"net/http"
 	"path"
+	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/securityfirst/tent/models"
 
 	"golang.org/x/oauth2"
 	lib "golang.org/x/oauth2/github"
@@ -22,10 +26,102 @@ type Config struct {
 	Secret    string
 	OAuthHost string
 	Host      string
-	State     string
+	PKCE      bool
+	Redirects []string
 	Login     HandleConf
 	Logout    HandleConf
 	Callback  HandleConf
+	Session   HandleConf
+	Cookie    CookieConf
+	APIKeys   string
+	Bot       BotConf
+	OIDC      OIDCConf
+	Roles     map[string][]string
+	Scopes    []string
+}
+
+// Scopes requested to the users
+var (
+	DefaultScopes  = []string{"user:email", "repo"}
+	IdentityScopes = []string{"read:user", "user:email"}
+)
+
+// BotConf is the identity used for commits made with API keys,
+// its token is also used for commits of users logged with OIDC
+type BotConf struct {
+	Login string
+	Name  string
+	Email string
+	Token string
+}
+
+// User returns the bot as commit author
+func (b *BotConf) User() models.User {
+	return models.User{Login: b.Login, Name: b.Name, Email: b.Email}
+}
+
+func (c *Config) scopes() []string {
+	if len(c.Scopes) == 0 {
+		return DefaultScopes
+	}
+	return c.Scopes
+}
+
+// CookieConf contains the options for the session cookie,
+// Secure and HttpOnly are true if omitted
+type CookieConf struct {
+	Name     string
+	Secret   string
+	Domain   string
+	MaxAge   int
+	Secure   *bool
+	HttpOnly *bool
+	SameSite string
+}
+
+func (c *CookieConf) name() string {
+	if c.Name == "" {
+		return sessionName
+	}
+	return c.Name
+}
+
+func (c *CookieConf) duration() time.Duration {
+	if c.MaxAge <= 0 {
+		return sessionDuration
+	}
+	return time.Duration(c.MaxAge) * time.Second
+}
+
+func (c *CookieConf) secure() bool { return c.Secure == nil || *c.Secure }
+
+func (c *CookieConf) httpOnly() bool { return c.HttpOnly == nil || *c.HttpOnly }
+
+func (c *CookieConf) sameSite() http.SameSite {
+	switch strings.ToLower(c.SameSite) {
+	case "strict":
+		return http.SameSiteStrictMode
+	case "none":
+		return http.SameSiteNoneMode
+	case "default":
+		return http.SameSiteDefaultMode
+	default:
+		return http.SameSiteLaxMode
+	}
+}
+
+// cookie returns the session cookie, a negative maxAge deletes it
+func (c *CookieConf) cookie(value string, maxAge int) *http.Cookie {
+	return &http.Cookie{
+		Name:     c.name(),
+		Value:    value,
+		Path:     "/",
+		Domain:   c.Domain,
+		MaxAge:   maxAge,
+		Secure:   c.secure(),
+		HttpOnly: c.httpOnly(),
+		SameSite: c.sameSite(),
+	}
 }
 
 // OAuth return the oauth2 configuration struct
@@ -35,6 +131,6 @@ func (c *Config) OAuth(root *gin.RouterGroup) *oauth2.Config {
 		ClientSecret: c.Secret,
 		RedirectURL:  fmt.Sprint(c.OAuthHost, path.Clean(root.BasePath()+c.Callback.Endpoint)),
 		Endpoint:     lib.Endpoint,
-		Scopes:       []string{"user:email", "repo"},
+		Scopes:       c.scopes(),
 	}
 }
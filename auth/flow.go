@@ -0,0 +1,136 @@
+package auth
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+	"time"
+
+	"golang.org/x/oauth2"
+)
+
+const flowDuration = 10 * time.Minute
+
+var ErrState = errors.New("invalid oauth state")
+
+// loginFlow is the state of a login, kept in a signed cookie until the callback
+type loginFlow struct {
+	State    string `json:"s"`
+	Verifier string `json:"v,omitempty"`
+	Nonce    string `json:"n,omitempty"`
+	Redirect string `json:"r,omitempty"`
+	Expires  int64  `json:"e"`
+}
+
+func newLoginFlow(pkce bool, redirect string) *loginFlow {
+	f := loginFlow{
+		State:    randomString(32),
+		Redirect: redirect,
+		Expires:  time.Now().Add(flowDuration).Unix(),
+	}
+	if pkce {
+		f.Verifier = randomString(32)
+	}
+	return &f
+}
+
+// options returns the parameters for the authorization URL
+func (f *loginFlow) options() []oauth2.AuthCodeOption {
+	opts := []oauth2.AuthCodeOption{oauth2.AccessTypeOnline}
+	if f.Nonce != "" {
+		opts = append(opts, oauth2.SetAuthURLParam("nonce", f.Nonce))
+	}
+	if f.Verifier != "" {
+		sum := sha256.Sum256([]byte(f.Verifier))
+		opts = append(opts,
+			oauth2.SetAuthURLParam("code_challenge", base64.RawURLEncoding.EncodeToString(sum[:])),
+			oauth2.SetAuthURLParam("code_challenge_method", "S256"),
+		)
+	}
+	return opts
+}
+
+// exchangeOptions returns the parameters for the token exchange
+func (f *loginFlow) exchangeOptions() []oauth2.AuthCodeOption {
+	if f.Verifier == "" {
+		return nil
+	}
+	return []oauth2.AuthCodeOption{oauth2.SetAuthURLParam("code_verifier", f.Verifier)}
+}
+
+func (c *CookieConf) flowName() string { return c.name() + "-login" }
+
+// flowCookie returns the cookie for the login flow, a nil flow deletes it
+func (c *CookieConf) flowCookie(s *sessionStore, f *loginFlow) *http.Cookie {
+	cookie := c.cookie("", -1)
+	cookie.Name, cookie.HttpOnly = c.flowName(), true
+	// the cookie must survive the redirect from the provider
+	if cookie.SameSite == http.SameSiteStrictMode {
+		cookie.SameSite = http.SameSiteLaxMode
+	}
+	if f != nil {
+		b, _ := json.Marshal(f)
+		cookie.Value = s.sign(base64.RawURLEncoding.EncodeToString(b))
+		cookie.MaxAge = int(flowDuration.Seconds())
+	}
+	return cookie
+}
+
+// readFlow verifies the flow cookie and checks the state
+func (c *CookieConf) readFlow(s *sessionStore, r *http.Request, state string) (*loginFlow, error) {
+	cookie, err := r.Cookie(c.flowName())
+	if err != nil {
+		return nil, ErrState
+	}
+	v, err := s.verify(cookie.Value)
+	if err != nil {
+		return nil, ErrState
+	}
+	b, err := base64.RawURLEncoding.DecodeString(v)
+	if err != nil {
+		return nil, ErrState
+	}
+	var f loginFlow
+	if err := json.Unmarshal(b, &f); err != nil {
+		return nil, ErrState
+	}
+	if time.Now().Unix() > f.Expires || !equalString(f.State, state) {
+		return nil, ErrState
+	}
+	return &f, nil
+}
+
+// validRedirect accepts local paths and URLs under one of the allowed ones
+func validRedirect(target string, allowed []string) bool {
+	if target == "" {
+		return true
+	}
+	u, err := url.Parse(target)
+	if err != nil {
+		return false
+	}
+	if u.Scheme == "" && u.Host == "" {
+		return strings.HasPrefix(target, "/") && !strings.HasPrefix(target, "//") && !strings.HasPrefix(target, "/\\")
+	}
+	for _, a := range allowed {
+		v, err := url.Parse(a)
+		if err != nil {
+			continue
+		}
+		if u.Scheme == v.Scheme && u.Host == v.Host && underPath(u.Path, v.Path) {
+			return true
+		}
+	}
+	return false
+}
+
+// underPath tells if the cleaned path is the base or one of its descendants
+func underPath(p, base string) bool {
+	p, base = path.Clean("/"+p), path.Clean("/"+base)
+	return base == "/" || p == base || strings.HasPrefix(p, base+"/")
+}
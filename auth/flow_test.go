@@ -0,0 +1,32 @@
+package auth
+
+import "testing"
+
+func TestValidRedirect(t *testing.T) {
+	allowed := []string{"https://tent.org/editor", "https://app.tent.org"}
+	var testCases = []struct {
+		target string
+		valid  bool
+	}{
+		{"", true},
+		{"/editor", true},
+		{"//evil.org", false},
+		{"/\\evil.org", false},
+		{"https://tent.org/editor", true},
+		{"https://tent.org/editor/", true},
+		{"https://tent.org/editor/page?a=b", true},
+		{"https://tent.org/editor-evil", false},
+		{"https://tent.org/editor/../admin", false},
+		{"https://tent.org/editor/%2e%2e/admin", false},
+		{"https://tent.org/other", false},
+		{"http://tent.org/editor", false},
+		{"https://evil.org/editor", false},
+		{"https://app.tent.org/any/page", true},
+		{"https://app.tent.org.evil.org/", false},
+	}
+	for _, tc := range testCases {
+		if v := validRedirect(tc.target, allowed); v != tc.valid {
+			t.Errorf("%s: expected %v, got %v", tc.target, tc.valid, v)
+		}
+	}
+}
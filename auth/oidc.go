@@ -0,0 +1,257 @@
+package auth
+
+import (
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/jws"
+
+	"github.com/securityfirst/tent/models"
+)
+
+const discoveryPath = "/.well-known/openid-configuration"
+
+// Providers of the user identities
+const (
+	ProviderGitHub = "github"
+	ProviderOIDC   = "oidc"
+	ProviderKey    = "key"
+)
+
+var ErrIDToken = errors.New("invalid id token")
+
+// OIDCConf contains info about a generic OpenID Connect provider
+type OIDCConf struct {
+	Issuer string
+	Scopes []string
+	Claims ClaimsConf
+}
+
+// ClaimsConf maps the ID token claims to the user fields, ID is the
+// immutable claim that identifies the user in roles
+type ClaimsConf struct {
+	ID    string
+	Login string
+	Name  string
+	Email string
+}
+
+func (c *ClaimsConf) id() string    { return orDefault(c.ID, "sub") }
+func (c *ClaimsConf) login() string { return orDefault(c.Login, "preferred_username") }
+func (c *ClaimsConf) name() string  { return orDefault(c.Name, "name") }
+func (c *ClaimsConf) email() string { return orDefault(c.Email, "email") }
+
+func orDefault(v, def string) string {
+	if v == "" {
+		return def
+	}
+	return v
+}
+
+// discovery is the subset of the provider metadata used
+type discovery struct {
+	Issuer   string `json:"issuer"`
+	AuthURL  string `json:"authorization_endpoint"`
+	TokenURL string `json:"token_endpoint"`
+	JWKSURL  string `json:"jwks_uri"`
+}
+
+type jwks struct {
+	Keys []struct {
+		Kid string `json:"kid"`
+		Kty string `json:"kty"`
+		N   string `json:"n"`
+		E   string `json:"e"`
+	} `json:"keys"`
+}
+
+// oidcProvider discovers the provider lazily and verifies its ID tokens
+type oidcProvider struct {
+	sync.Mutex
+	conf   OIDCConf
+	client *http.Client
+	doc    *discovery
+	keys   map[string]*rsa.PublicKey
+}
+
+func newOIDCProvider(conf OIDCConf) *oidcProvider {
+	return &oidcProvider{
+		conf:   conf,
+		client: &http.Client{Timeout: 10 * time.Second},
+		keys:   make(map[string]*rsa.PublicKey),
+	}
+}
+
+// OAuth returns the oauth2 configuration, based on the GitHub one
+func (p *oidcProvider) OAuth(base *oauth2.Config) (*oauth2.Config, error) {
+	doc, err := p.discover()
+	if err != nil {
+		return nil, err
+	}
+	conf := *base
+	conf.Endpoint = oauth2.Endpoint{AuthURL: doc.AuthURL, TokenURL: doc.TokenURL}
+	conf.Scopes = append([]string{"openid", "profile", "email"}, p.conf.Scopes...)
+	return &conf, nil
+}
+
+func (p *oidcProvider) discover() (*discovery, error) {
+	p.Lock()
+	defer p.Unlock()
+	if p.doc != nil {
+		return p.doc, nil
+	}
+	var doc discovery
+	if err := p.get(strings.TrimSuffix(p.conf.Issuer, "/")+discoveryPath, &doc); err != nil {
+		return nil, fmt.Errorf("oidc discovery: %s", err)
+	}
+	if doc.Issuer != p.conf.Issuer {
+		return nil, fmt.Errorf("oidc discovery: issuer %q, expected %q", doc.Issuer, p.conf.Issuer)
+	}
+	p.doc = &doc
+	return p.doc, nil
+}
+
+// key returns the public key with the given ID, keys are refreshed when missing
+func (p *oidcProvider) key(kid string) (*rsa.PublicKey, error) {
+	doc, err := p.discover()
+	if err != nil {
+		return nil, err
+	}
+	p.Lock()
+	defer p.Unlock()
+	if k, ok := p.keys[kid]; ok {
+		return k, nil
+	}
+	var set jwks
+	if err := p.get(doc.JWKSURL, &set); err != nil {
+		return nil, fmt.Errorf("oidc keys: %s", err)
+	}
+	for _, k := range set.Keys {
+		if k.Kty != "RSA" {
+			continue
+		}
+		n, err := base64.RawURLEncoding.DecodeString(k.N)
+		if err != nil {
+			continue
+		}
+		e, err := base64.RawURLEncoding.DecodeString(k.E)
+		if err != nil {
+			continue
+		}
+		p.keys[k.Kid] = &rsa.PublicKey{
+			N: new(big.Int).SetBytes(n),
+			E: int(new(big.Int).SetBytes(e).Int64()),
+		}
+	}
+	if k, ok := p.keys[kid]; ok {
+		return k, nil
+	}
+	return nil, fmt.Errorf("oidc keys: unknown key %q", kid)
+}
+
+func (p *oidcProvider) get(url string, v interface{}) error {
+	resp, err := p.client.Get(url)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s: %s", url, resp.Status)
+	}
+	return json.NewDecoder(resp.Body).Decode(v)
+}
+
+// Verify checks signature, issuer, audience, expiry and nonce of the ID token
+func (p *oidcProvider) Verify(raw, clientID, nonce string) (map[string]interface{}, error) {
+	parts := strings.Split(raw, ".")
+	if len(parts) != 3 {
+		return nil, ErrIDToken
+	}
+	var header jws.Header
+	if err := decodeSegment(parts[0], &header); err != nil {
+		return nil, ErrIDToken
+	}
+	if header.Algorithm != "RS256" {
+		return nil, fmt.Errorf("%s: unsupported algorithm %q", ErrIDToken, header.Algorithm)
+	}
+	key, err := p.key(header.KeyID)
+	if err != nil {
+		return nil, err
+	}
+	if err := jws.Verify(raw, key); err != nil {
+		return nil, fmt.Errorf("%s: %s", ErrIDToken, err)
+	}
+	var claims map[string]interface{}
+	if err := decodeSegment(parts[1], &claims); err != nil {
+		return nil, ErrIDToken
+	}
+	if iss, _ := claims["iss"].(string); iss != p.doc.Issuer {
+		return nil, fmt.Errorf("%s: issuer %q", ErrIDToken, iss)
+	}
+	if !hasAudience(claims["aud"], clientID) {
+		return nil, fmt.Errorf("%s: audience", ErrIDToken)
+	}
+	if exp, _ := claims["exp"].(float64); time.Now().Unix() > int64(exp) {
+		return nil, fmt.Errorf("%s: expired", ErrIDToken)
+	}
+	if n, _ := claims["nonce"].(string); !equalString(n, nonce) {
+		return nil, fmt.Errorf("%s: nonce", ErrIDToken)
+	}
+	return claims, nil
+}
+
+// User maps the claims to a user
+func (p *oidcProvider) User(claims map[string]interface{}) (models.User, error) {
+	claim := func(name string) string { s, _ := claims[name].(string); return s }
+	id := claim(p.conf.Claims.id())
+	if id == "" {
+		return models.User{}, fmt.Errorf("%s: no user", ErrIDToken)
+	}
+	u := models.User{
+		ID:    ProviderOIDC + ":" + id,
+		Login: claim(p.conf.Claims.login()),
+		Name:  claim(p.conf.Claims.name()),
+		Email: claim(p.conf.Claims.email()),
+	}
+	if u.Login == "" {
+		u.Login = id
+	}
+	if u.Name == "" {
+		u.Name = u.Login
+	}
+	if u.Email == "" {
+		u.Email = u.Login + "@tent.org"
+	}
+	return u, nil
+}
+
+func hasAudience(aud interface{}, id string) bool {
+	switch v := aud.(type) {
+	case string:
+		return v == id
+	case []interface{}:
+		for _, a := range v {
+			if a == id {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func decodeSegment(s string, v interface{}) error {
+	b, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(s, "="))
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(b, v)
+}
@@ -0,0 +1,200 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"golang.org/x/oauth2/jws"
+
+	"github.com/securityfirst/tent/models"
+)
+
+// mockOIDC is a minimal OpenID Connect provider
+type mockOIDC struct {
+	*httptest.Server
+	key    *rsa.PrivateKey
+	signer *rsa.PrivateKey
+	nonce  string
+	aud    string
+}
+
+func newMockOIDC(t *testing.T) *mockOIDC {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := mockOIDC{key: key, signer: key, aud: "client"}
+	mux := http.NewServeMux()
+	mux.HandleFunc(discoveryPath, func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]string{
+			"issuer":                 m.URL,
+			"authorization_endpoint": m.URL + "/authorize",
+			"token_endpoint":         m.URL + "/token",
+			"jwks_uri":               m.URL + "/jwks",
+		})
+	})
+	mux.HandleFunc("/jwks", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]interface{}{"keys": []map[string]string{{
+			"kid": "k1",
+			"kty": "RSA",
+			"n":   base64.RawURLEncoding.EncodeToString(m.key.N.Bytes()),
+			"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(m.key.E)).Bytes()),
+		}}})
+	})
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		now := time.Now()
+		idToken, err := jws.Encode(&jws.Header{Algorithm: "RS256", Typ: "JWT", KeyID: "k1"}, &jws.ClaimSet{
+			Iss: m.URL,
+			Aud: m.aud,
+			Sub: "1234",
+			Iat: now.Unix(),
+			Exp: now.Add(time.Hour).Unix(),
+			PrivateClaims: map[string]interface{}{
+				"nonce":              m.nonce,
+				"preferred_username": "editor",
+				"name":               "Jane Editor",
+				"email":              "jane@example.org",
+			},
+		}, m.signer)
+		if err != nil {
+			t.Fatal(err)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"access_token": "access",
+			"token_type":   "Bearer",
+			"expires_in":   3600,
+			"id_token":     idToken,
+		})
+	})
+	m.Server = httptest.NewServer(mux)
+	return &m
+}
+
+func newOIDCRouter(t *testing.T, issuer string) (*gin.Engine, *models.User, *string) {
+	gin.SetMode(gin.TestMode)
+	var (
+		router = gin.New()
+		user   = new(models.User)
+		token  = new(string)
+		conf   = Config{
+			ID:        "client",
+			Secret:    "secret",
+			OAuthHost: "http://tent.local",
+			Login:     HandleConf{Endpoint: "/auth/login"},
+			Logout:    HandleConf{Endpoint: "/auth/logout"},
+			Callback:  HandleConf{Endpoint: "/auth/callback"},
+			Bot:       BotConf{Token: "deploy"},
+			OIDC:      OIDCConf{Issuer: issuer},
+		}
+		e, err = NewEngine(conf, &router.RouterGroup)
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	router.PUT("/edit", e.EnsureUser, func(c *gin.Context) {
+		*user, *token = c.MustGet("user").(models.User), c.MustGet("token").(string)
+	})
+	return router, user, token
+}
+
+func serve(router http.Handler, r *http.Request, cookies []*http.Cookie) *httptest.ResponseRecorder {
+	for _, c := range cookies {
+		r.AddCookie(c)
+	}
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, r)
+	return w
+}
+
+func oidcLogin(t *testing.T, m *mockOIDC, router http.Handler) *httptest.ResponseRecorder {
+	w := serve(router, httptest.NewRequest("GET", "/auth/login", nil), nil)
+	if w.Code != http.StatusTemporaryRedirect {
+		t.Fatalf("login: expected redirect, got %d %s", w.Code, w.Body)
+	}
+	loc, err := url.Parse(w.Header().Get("Location"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m.nonce == "" {
+		m.nonce = loc.Query().Get("nonce")
+	}
+	callback := "/auth/callback?code=code&state=" + url.QueryEscape(loc.Query().Get("state"))
+	return serve(router, httptest.NewRequest("GET", callback, nil), w.Result().Cookies())
+}
+
+func TestOIDCLogin(t *testing.T) {
+	m := newMockOIDC(t)
+	defer m.Close()
+	router, user, token := newOIDCRouter(t, m.URL)
+
+	w := oidcLogin(t, m, router)
+	if w.Code != http.StatusOK {
+		t.Fatalf("callback: expected 200, got %d %s", w.Code, w.Body)
+	}
+	var resp struct {
+		CSRF string `json:"csrf_token"`
+	}
+	if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+		t.Fatal(err)
+	}
+	var cookies []*http.Cookie
+	for _, c := range w.Result().Cookies() {
+		if c.Name == sessionName {
+			cookies = append(cookies, c)
+		}
+	}
+
+	r := httptest.NewRequest("PUT", "/edit", nil)
+	if w := serve(router, r, cookies); w.Code != http.StatusForbidden {
+		t.Fatalf("edit without csrf: expected 403, got %d", w.Code)
+	}
+	r = httptest.NewRequest("PUT", "/edit", nil)
+	r.Header.Set(csrfHeader, resp.CSRF)
+	if w := serve(router, r, cookies); w.Code != http.StatusOK {
+		t.Fatalf("edit: expected 200, got %d %s", w.Code, w.Body)
+	}
+	expected := models.User{ID: "oidc:1234", Login: "editor", Name: "Jane Editor", Email: "jane@example.org"}
+	if *user != expected {
+		t.Errorf("expected user %v, got %v", expected, *user)
+	}
+	if *token != "deploy" {
+		t.Errorf("expected deploy token, got %q", *token)
+	}
+}
+
+func TestOIDCInvalidToken(t *testing.T) {
+	for name, tamper := range map[string]func(*mockOIDC){
+		"nonce":     func(m *mockOIDC) { m.nonce = "wrong" },
+		"audience":  func(m *mockOIDC) { m.aud = "other" },
+		"signature": func(m *mockOIDC) { m.signer, _ = rsa.GenerateKey(rand.Reader, 2048) },
+	} {
+		m := newMockOIDC(t)
+		tamper(m)
+		router, _, _ := newOIDCRouter(t, m.URL)
+		if w := oidcLogin(t, m, router); w.Code != http.StatusUnauthorized {
+			t.Errorf("%s: expected 401, got %d %s", name, w.Code, w.Body)
+		}
+		m.Close()
+	}
+}
+
+func TestOIDCUser(t *testing.T) {
+	p := newOIDCProvider(OIDCConf{})
+	u, err := p.User(map[string]interface{}{"sub": "42", "preferred_username": "admin"})
+	if err != nil || u.ID != "oidc:42" || u.Login != "admin" {
+		t.Errorf("unexpected user %+v: %v", u, err)
+	}
+	if _, err := p.User(map[string]interface{}{"preferred_username": "admin"}); err == nil {
+		t.Error("expected error without sub")
+	}
+}
@@ -0,0 +1,65 @@
+package auth
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/securityfirst/tent/models"
+)
+
+// Roles of the users, admins have all permissions
+const (
+	RoleAdmin     = "admin"
+	RoleReviewer  = "reviewer"
+	RolePublisher = "publisher"
+)
+
+// HasRole tells if the user has the role, admins have all roles.
+// Members are identities like github:login or oidc:sub, a bare login is a GitHub one.
+func (e *Engine) HasRole(u models.User, role string) bool {
+	if u.ID == "" {
+		return false
+	}
+	for _, r := range []string{role, RoleAdmin} {
+		for _, member := range e.roles[r] {
+			if memberID(member) == u.ID {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// HasPublishers tells if any identity has the publisher or admin role,
+// without one the workflow is not enforced
+func (e *Engine) HasPublishers() bool {
+	return len(e.roles[RolePublisher]) > 0 || len(e.roles[RoleAdmin]) > 0
+}
+
+// memberID returns the identity of a role member, GitHub logins are case insensitive
+func memberID(member string) string {
+	if !strings.Contains(member, ":") {
+		member = ProviderGitHub + ":" + member
+	}
+	if strings.HasPrefix(member, ProviderGitHub+":") {
+		return strings.ToLower(member)
+	}
+	return member
+}
+
+// RequireRole returns an handler that allows only users with one of the roles,
+// it must be used after EnsureUser
+func (e *Engine) RequireRole(roles ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		u := c.MustGet("user").(models.User)
+		for _, r := range roles {
+			if e.HasRole(u, r) {
+				return
+			}
+		}
+		c.JSON(http.StatusForbidden, gin.H{"error": "permission denied"})
+		c.Abort()
+	}
+}
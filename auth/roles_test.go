@@ -0,0 +1,49 @@
+package auth
+
+import (
+	"testing"
+
+	"github.com/securityfirst/tent/models"
+)
+
+func TestHasRole(t *testing.T) {
+	e := Engine{roles: map[string][]string{
+		RoleAdmin:     {"octocat"},
+		RolePublisher: {"github:Editor", "oidc:1234"},
+	}}
+	var testCases = []struct {
+		user models.User
+		role string
+		has  bool
+	}{
+		{models.User{ID: "github:octocat", Login: "OctoCat"}, RolePublisher, true},
+		{models.User{ID: "github:editor", Login: "Editor"}, RolePublisher, true},
+		{models.User{ID: "github:editor", Login: "Editor"}, RoleAdmin, false},
+		{models.User{ID: "oidc:1234", Login: "jane"}, RolePublisher, true},
+		{models.User{ID: "oidc:5678", Login: "editor"}, RolePublisher, false},
+		{models.User{ID: "oidc:octocat", Login: "octocat"}, RoleAdmin, false},
+		{models.User{Login: "octocat"}, RoleAdmin, false},
+	}
+	for _, tc := range testCases {
+		if has := e.HasRole(tc.user, tc.role); has != tc.has {
+			t.Errorf("%+v %s: expected %v, got %v", tc.user, tc.role, tc.has, has)
+		}
+	}
+}
+
+func TestHasPublishers(t *testing.T) {
+	for _, tc := range []struct {
+		roles map[string][]string
+		has   bool
+	}{
+		{nil, false},
+		{map[string][]string{RoleReviewer: {"octocat"}}, false},
+		{map[string][]string{RolePublisher: {"octocat"}}, true},
+		{map[string][]string{RoleAdmin: {"octocat"}}, true},
+	} {
+		e := Engine{roles: tc.roles}
+		if has := e.HasPublishers(); has != tc.has {
+			t.Errorf("%v: expected %v, got %v", tc.roles, tc.has, has)
+		}
+	}
+}
@@ -0,0 +1,139 @@
+package auth
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/securityfirst/tent/models"
+)
+
+var (
+	ErrSession   = errors.New("invalid session")
+	ErrSignature = errors.New("invalid signature")
+)
+
+// session maps an opaque ID to the access token of the user,
+// or to the user itself for providers other than GitHub
+type session struct {
+	Token   string
+	User    *models.User
+	CSRF    string
+	Expires time.Time
+}
+
+// sessionStore keeps the sessions in memory, IDs sent to the browser are signed
+type sessionStore struct {
+	sync.RWMutex
+	secret   []byte
+	duration time.Duration
+	sessions map[string]*session
+}
+
+func newSessionStore(secret string, duration time.Duration) *sessionStore {
+	var key = []byte(secret)
+	if len(key) == 0 {
+		key = randomBytes(32)
+	}
+	return &sessionStore{
+		secret:   key,
+		duration: duration,
+		sessions: make(map[string]*session),
+	}
+}
+
+// Create starts a new session for the token or user and returns its signed ID
+func (s *sessionStore) Create(token string, user *models.User) (string, *session) {
+	var (
+		id   = randomString(32)
+		sess = &session{
+			Token:   token,
+			User:    user,
+			CSRF:    randomString(32),
+			Expires: time.Now().Add(s.duration),
+		}
+	)
+	s.Lock()
+	defer s.Unlock()
+	s.prune()
+	s.sessions[id] = sess
+	return s.sign(id), sess
+}
+
+// Get returns the session of a signed ID
+func (s *sessionStore) Get(signed string) (*session, error) {
+	id, err := s.verify(signed)
+	if err != nil {
+		return nil, err
+	}
+	s.RLock()
+	sess, ok := s.sessions[id]
+	s.RUnlock()
+	if !ok || time.Now().After(sess.Expires) {
+		return nil, ErrSession
+	}
+	return sess, nil
+}
+
+// Delete removes the session of a signed ID
+func (s *sessionStore) Delete(signed string) {
+	id, err := s.verify(signed)
+	if err != nil {
+		return
+	}
+	s.Lock()
+	defer s.Unlock()
+	delete(s.sessions, id)
+}
+
+func (s *sessionStore) prune() {
+	now := time.Now()
+	for id, sess := range s.sessions {
+		if now.After(sess.Expires) {
+			delete(s.sessions, id)
+		}
+	}
+}
+
+func (s *sessionStore) sign(v string) string {
+	return v + "." + s.mac(v)
+}
+
+func (s *sessionStore) verify(signed string) (string, error) {
+	parts := strings.Split(signed, ".")
+	if len(parts) != 2 {
+		return "", ErrSignature
+	}
+	if !hmac.Equal([]byte(parts[1]), []byte(s.mac(parts[0]))) {
+		return "", ErrSignature
+	}
+	return parts[0], nil
+}
+
+func (s *sessionStore) mac(v string) string {
+	h := hmac.New(sha256.New, s.secret)
+	h.Write([]byte(v))
+	return base64.RawURLEncoding.EncodeToString(h.Sum(nil))
+}
+
+func randomBytes(n int) []byte {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		panic(err)
+	}
+	return b
+}
+
+func randomString(n int) string {
+	return base64.RawURLEncoding.EncodeToString(randomBytes(n))
+}
+
+func equalString(a, b string) bool {
+	return subtle.ConstantTimeCompare([]byte(a), []byte(b)) == 1
+}
@@ -0,0 +1,81 @@
+package auth
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/securityfirst/tent/models"
+)
+
+func TestSessionStore(t *testing.T) {
+	s := newSessionStore("secret", time.Hour)
+	id, sess := s.Create("token", nil)
+	got, err := s.Get(id)
+	if err != nil || got != sess || got.Token != "token" {
+		t.Fatalf("unexpected session %v: %v", got, err)
+	}
+	if _, err := s.Get(id + "x"); err != ErrSignature {
+		t.Errorf("expected %v, got %v", ErrSignature, err)
+	}
+	if _, err := newSessionStore("other", time.Hour).Get(id); err != ErrSignature {
+		t.Errorf("expected %v with another secret, got %v", ErrSignature, err)
+	}
+	s.Delete(id)
+	if _, err := s.Get(id); err != ErrSession {
+		t.Errorf("expected %v after delete, got %v", ErrSession, err)
+	}
+	id, sess = s.Create("token", nil)
+	sess.Expires = time.Now().Add(-time.Second)
+	if _, err := s.Get(id); err != ErrSession {
+		t.Errorf("expected %v after expiry, got %v", ErrSession, err)
+	}
+}
+
+func TestCookieConf(t *testing.T) {
+	var c CookieConf
+	if cookie := c.cookie("v", 1); !cookie.Secure || !cookie.HttpOnly || cookie.Name != sessionName {
+		t.Errorf("unexpected default cookie %+v", cookie)
+	}
+	off := false
+	c = CookieConf{Secure: &off, HttpOnly: &off}
+	if cookie := c.cookie("v", 1); cookie.Secure || cookie.HttpOnly {
+		t.Errorf("unexpected cookie %+v", cookie)
+	}
+}
+
+func TestCSRF(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	e := Engine{
+		sessions: newSessionStore("secret", time.Hour),
+		bot:      BotConf{Token: "bot"},
+	}
+	id, sess := e.sessions.Create("", &models.User{Login: "user"})
+	r := gin.New()
+	r.Any("/", e.EnsureUser, func(c *gin.Context) { c.Status(http.StatusNoContent) })
+	var testCases = []struct {
+		method string
+		csrf   string
+		status int
+	}{
+		{http.MethodGet, "", http.StatusNoContent},
+		{http.MethodPost, "", http.StatusForbidden},
+		{http.MethodPut, "wrong", http.StatusForbidden},
+		{http.MethodDelete, sess.CSRF, http.StatusNoContent},
+	}
+	for _, tc := range testCases {
+		req := httptest.NewRequest(tc.method, "/", nil)
+		req.AddCookie(&http.Cookie{Name: sessionName, Value: id})
+		if tc.csrf != "" {
+			req.Header.Set(csrfHeader, tc.csrf)
+		}
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		if w.Code != tc.status {
+			t.Errorf("%s %q: expected %d, got %d", tc.method, tc.csrf, tc.status, w.Code)
+		}
+	}
+}
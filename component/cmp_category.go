@@ -7,11 +7,13 @@ import (
 )
 
 type Category struct {
-	ID            string  `json:"id"`
-	Name          string  `json:"name"`
-	Hash          string  `json:"hash"`
-	Locale        string  `json:"-"`
-	Order         float64 `json:"-"`
+	ID            string   `json:"id"`
+	Name          string   `json:"name"`
+	Hash          string   `json:"hash"`
+	Locale        string   `json:"-"`
+	Order         float64  `json:"-"`
+	Tags          []string `json:"tags,omitempty"`
+	Extra         Extra    `json:"extra,omitempty"`
 	subcategories []*Subcategory
 }
 
@@ -32,16 +34,20 @@ func (c *Category) SHA() string {
 	return c.Hash
 }
 
-func (c *Category) Tree(html bool) interface{} {
+func (c *Category) Tree(html, all bool) interface{} {
 	var subs = make([]interface{}, 0, len(c.subcategories))
 	for i := range c.subcategories {
-		subs = append(subs, c.subcategories[i].Tree(html))
+		subs = append(subs, c.subcategories[i].Tree(html, all))
 	}
-	return map[string]interface{}{
+	var m = map[string]interface{}{
 		"id":            c.ID,
 		"name":          c.Name,
 		"subcategories": subs,
 	}
+	if len(c.Tags) != 0 {
+		m["tags"] = c.Tags
+	}
+	return m
 }
 
 func (c *Category) MarshalJSON() ([]byte, error) {
@@ -52,6 +58,12 @@ func (c *Category) MarshalJSON() ([]byte, error) {
 	if c.Hash != "" {
 		m["hash"] = c.Hash
 	}
+	if len(c.Tags) != 0 {
+		m["tags"] = c.Tags
+	}
+	if len(c.Extra) != 0 {
+		m["extra"] = c.Extra
+	}
 	return json.Marshal(m)
 }
 
@@ -103,15 +115,18 @@ func (c *Category) SetPath(filepath string) error {
 	return nil
 }
 
-func (*Category) order() []string     { return []string{"Name", "Order"} }
-func (*Category) optionals() []string { return nil }
-func (c *Category) pointers() args    { return args{&c.Name, &c.Order} }
-func (c *Category) values() args      { return args{c.Name, c.Order} }
+func (*Category) order() []string     { return []string{"Name", "Order", "Tags"} }
+func (*Category) optionals() []string { return []string{"Tags"} }
+func (c *Category) pointers() args    { return args{&c.Name, &c.Order, &c.Tags} }
+func (c *Category) extra() *Extra     { return &c.Extra }
+func (c *Category) values() args      { return args{c.Name, c.Order, c.Tags} }
 
 func (c *Category) Contents() string {
 	return getMeta(c)
 }
 
+func (c *Category) frontMatter() (string, error) { return writeFrontMatter(metaMap(c)) }
+
 func (c *Category) SetContents(contents string) error {
 	return setMeta(contents, c)
 }
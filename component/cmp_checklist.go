@@ -5,12 +5,49 @@ import (
 	"fmt"
 	"regexp"
 	"strings"
+	"time"
+
+	yaml "gopkg.in/yaml.v2"
 )
 
 type Checklist struct {
-	parent *Difficulty
-	Hash   string  `json:"hash"`
-	Checks []Check `json:"checks"`
+	parent       *Difficulty
+	Hash         string     `json:"hash"`
+	Status       string     `json:"status,omitempty"`
+	LastReviewed *time.Time `json:"last_reviewed,omitempty"`
+	ReviewEvery  int        `json:"review_every,omitempty"`
+	Extra        Extra      `json:"extra,omitempty"`
+	Checks       []Check    `json:"checks"`
+}
+
+// checklistHeader is the optional first block of a checklist
+type checklistHeader struct {
+	Status       string
+	LastReviewed *time.Time
+	ReviewEvery  int
+	Extra        Extra
+}
+
+func (*checklistHeader) order() []string { return []string{"Status", "LastReviewed", "ReviewEvery"} }
+func (*checklistHeader) optionals() []string {
+	return []string{"Status", "LastReviewed", "ReviewEvery"}
+}
+func (h *checklistHeader) pointers() args { return args{&h.Status, &h.LastReviewed, &h.ReviewEvery} }
+func (h *checklistHeader) extra() *Extra  { return &h.Extra }
+func (h *checklistHeader) values() args   { return args{h.Status, h.LastReviewed, h.ReviewEvery} }
+
+func (h *checklistHeader) isZero() bool {
+	return h.Status == "" && h.LastReviewed == nil && h.ReviewEvery == 0 && len(h.Extra) == 0
+}
+
+// isChecklistHeader tells if the block is the header of a checklist, that has no text
+func isChecklistHeader(block string) bool {
+	for _, row := range strings.Split(block, "\n") {
+		if m := metaRow.FindStringSubmatch(row); len(m) == 3 && m[1] == "Text" {
+			return false
+		}
+	}
+	return true
 }
 
 func (c *Checklist) Resource() Resource {
@@ -29,11 +66,13 @@ func (c *Checklist) Resource() Resource {
 type Check struct {
 	Text    string `json:"text"`
 	NoCheck bool   `json:"no_check"`
+	Extra   Extra  `json:"extra,omitempty"`
 }
 
 func (*Check) order() []string     { return []string{"Text", "NoCheck"} }
 func (*Check) optionals() []string { return nil }
 func (c *Check) pointers() args    { return args{&c.Text, &c.NoCheck} }
+func (c *Check) extra() *Extra     { return &c.Extra }
 func (c *Check) values() args      { return args{c.Text, c.NoCheck} }
 
 func (c *Checklist) SetParent(d *Difficulty) {
@@ -62,10 +101,25 @@ func (*Checklist) SetPath(filepath string) error {
 	return nil
 }
 
+func (c *Checklist) State() string          { return c.Status }
+func (c *Checklist) SetState(status string) { c.Status = status }
+
+func (c *Checklist) extra() *Extra { return &c.Extra }
+
+func (c *Checklist) Review() (last *time.Time, every int) { return c.LastReviewed, c.ReviewEvery }
+
+func (c *Checklist) header() *checklistHeader {
+	return &checklistHeader{Status: c.Status, LastReviewed: c.LastReviewed, ReviewEvery: c.ReviewEvery, Extra: c.Extra}
+}
+
 func (c *Checklist) Contents() string {
+	h := c.header()
 	b := bytes.NewBuffer(nil)
+	if !h.isZero() {
+		fmt.Fprint(b, getMeta(h))
+	}
 	for i := range c.Checks {
-		if i != 0 {
+		if b.Len() > 0 {
 			fmt.Fprint(b, bodySeparator)
 		}
 		fmt.Fprint(b, getMeta(&c.Checks[i]))
@@ -73,6 +127,14 @@ func (c *Checklist) Contents() string {
 	return b.String()
 }
 
+func (c *Checklist) frontMatter() (string, error) {
+	checks := make([]yaml.MapSlice, len(c.Checks))
+	for i := range c.Checks {
+		checks[i] = metaMap(&c.Checks[i])
+	}
+	return writeFrontMatter(append(metaMap(c.header()), yaml.MapItem{Key: "Checks", Value: checks}))
+}
+
 func (c *Checklist) SetContents(contents string) error {
 	if contents == "" {
 		if c.Checks != nil {
@@ -80,15 +142,66 @@ func (c *Checklist) SetContents(contents string) error {
 		}
 		return nil
 	}
+	var (
+		h      checklistHeader
+		checks []Check
+		err    error
+	)
+	if isFrontMatter(contents) {
+		checks, err = h.setFrontMatter(contents)
+	} else {
+		checks, err = h.setRows(contents)
+	}
+	if err != nil {
+		return err
+	}
+	if err := checkStatus(h.Status); err != nil {
+		return err
+	}
+	if err := checkReview(h.ReviewEvery); err != nil {
+		return err
+	}
+	c.Status, c.LastReviewed, c.ReviewEvery, c.Extra = h.Status, h.LastReviewed, h.ReviewEvery, h.Extra
+	c.Checks = checks
+	return nil
+}
+
+func (h *checklistHeader) setRows(contents string) ([]Check, error) {
 	parts := strings.Split(contents, bodySeparator)
+	if isChecklistHeader(parts[0]) {
+		if err := setRows(parts[0], h); err != nil {
+			return nil, err
+		}
+		parts = parts[1:]
+	}
 	var checks = make([]Check, len(parts))
 	for i, v := range parts {
-		if err := setMeta(v, &checks[i]); err != nil {
-			return err
+		if err := setRows(v, &checks[i]); err != nil {
+			return nil, err
 		}
 	}
-	c.Checks = checks
-	return nil
+	return checks, nil
+}
+
+func (h *checklistHeader) setFrontMatter(contents string) ([]Check, error) {
+	values, _, err := readFrontMatter(contents)
+	if err != nil {
+		return nil, err
+	}
+	list, err := popList(values, "Checks")
+	if err != nil {
+		return nil, err
+	}
+	if err := setMetaMap(values, h); err != nil {
+		return nil, err
+	}
+	var checks = make([]Check, len(list))
+	for i, v := range list {
+		if err := setMetaMap(v, &checks[i]); err != nil {
+			return nil, err
+		}
+	}
+	return checks, nil
 }
 
 func (c *Checklist) Add(v ...Check) { c.Checks = append(c.Checks, v...) }
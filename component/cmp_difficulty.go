@@ -8,9 +8,11 @@ import (
 
 type Difficulty struct {
 	parent    *Subcategory
-	ID        string `json:"id"`
-	Descr     string `json:"description"`
-	Hash      string `json:"hash"`
+	ID        string  `json:"id"`
+	Descr     string  `json:"description"`
+	Hash      string  `json:"hash"`
+	Order     float64 `json:"-"`
+	Extra     Extra   `json:"extra,omitempty"`
 	items     []*Item
 	checklist *Checklist
 }
@@ -28,7 +30,12 @@ func (d *Difficulty) HasChildren() bool {
 	return len(d.items) != 0
 }
 
-func (d *Difficulty) Tree(html bool) interface{} {
+// Tree returns the difficulty with its items and checks, all includes the
+// ones that are not published
+func (d *Difficulty) Tree(html, all bool) interface{} {
+	if !all {
+		d = d.Published()
+	}
 	var items = make([]Item, len(d.items))
 	for i, v := range d.items {
 		items[i] = *v
@@ -44,6 +51,21 @@ func (d *Difficulty) Tree(html bool) interface{} {
 	}
 }
 
+// Published returns a copy of the difficulty with only the published items and checks
+func (d *Difficulty) Published() *Difficulty {
+	v := *d
+	v.items = make([]*Item, 0, len(d.items))
+	for _, i := range d.items {
+		if Published(i) {
+			v.items = append(v.items, i)
+		}
+	}
+	if d.checklist != nil && !IsPublished(d.checklist.Status) {
+		v.checklist = &Checklist{parent: d, Checks: []Check{}}
+	}
+	return &v
+}
+
 func (d *Difficulty) SHA() string {
 	return d.Hash
 }
@@ -61,6 +83,9 @@ func (d *Difficulty) MarshalJSON() ([]byte, error) {
 	if d.Hash != "" {
 		m["hash"] = d.Hash
 	}
+	if len(d.Extra) != 0 {
+		m["extra"] = d.Extra
+	}
 	return json.Marshal(m)
 }
 
@@ -90,9 +115,13 @@ func (d *Difficulty) Checks() *Checklist {
 		dst[i] = v
 	}
 	return &Checklist{
-		parent: d,
-		Hash:   d.checklist.Hash,
-		Checks: dst,
+		parent:       d,
+		Hash:         d.checklist.Hash,
+		Status:       d.checklist.Status,
+		LastReviewed: d.checklist.LastReviewed,
+		ReviewEvery:  d.checklist.ReviewEvery,
+		Extra:        d.checklist.Extra,
+		Checks:       dst,
 	}
 }
 
@@ -147,13 +176,16 @@ func (d *Difficulty) SetPath(filepath string) error {
 	return nil
 }
 
-func (*Difficulty) order() []string     { return []string{"Description"} }
-func (*Difficulty) optionals() []string { return nil }
-func (d *Difficulty) pointers() args    { return args{&d.Descr} }
-func (d *Difficulty) values() args      { return args{d.Descr} }
+func (*Difficulty) order() []string     { return []string{"Description", "Order"} }
+func (*Difficulty) optionals() []string { return []string{"Order"} }
+func (d *Difficulty) pointers() args    { return args{&d.Descr, &d.Order} }
+func (d *Difficulty) extra() *Extra     { return &d.Extra }
+func (d *Difficulty) values() args      { return args{d.Descr, d.Order} }
 
 func (d *Difficulty) Contents() string { return getMeta(d) }
 
+func (d *Difficulty) frontMatter() (string, error) { return writeFrontMatter(metaMap(d)) }
+
 func (d *Difficulty) SetContents(contents string) error {
 	if d.checklist == nil {
 		d.checklist = new(Checklist)
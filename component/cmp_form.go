@@ -5,14 +5,21 @@ import (
 	"fmt"
 	"regexp"
 	"strings"
+	"time"
+
+	yaml "gopkg.in/yaml.v2"
 )
 
 type Form struct {
-	ID      string       `json:"id"`
-	Name    string       `json:"name"`
-	Hash    string       `json:"hash,omitempty"`
-	Locale  string       `json:"-"`
-	Screens []FormScreen `json:"screens,omitempty"`
+	ID        string       `json:"id"`
+	Name      string       `json:"name"`
+	Hash      string       `json:"hash,omitempty"`
+	Locale    string       `json:"-"`
+	Status    string       `json:"status,omitempty"`
+	PublishAt *time.Time   `json:"publish_at,omitempty"`
+	ExpireAt  *time.Time   `json:"expire_at,omitempty"`
+	Extra     Extra        `json:"extra,omitempty"`
+	Screens   []FormScreen `json:"screens,omitempty"`
 }
 
 func (f *Form) Resource() Resource {
@@ -59,10 +66,16 @@ func (f *Form) SetPath(filepath string) error {
 	return nil
 }
 
-func (*Form) order() []string     { return []string{"Name"} }
-func (*Form) optionals() []string { return nil }
-func (f *Form) pointers() args    { return args{&f.Name} }
-func (f *Form) values() args      { return args{f.Name} }
+func (*Form) order() []string     { return []string{"Name", "Status", "PublishAt", "ExpireAt"} }
+func (*Form) optionals() []string { return []string{"Status", "PublishAt", "ExpireAt"} }
+func (f *Form) pointers() args    { return args{&f.Name, &f.Status, &f.PublishAt, &f.ExpireAt} }
+func (f *Form) extra() *Extra     { return &f.Extra }
+func (f *Form) values() args      { return args{f.Name, f.Status, f.PublishAt, f.ExpireAt} }
+
+func (f *Form) State() string          { return f.Status }
+func (f *Form) SetState(status string) { f.Status = status }
+
+func (f *Form) Schedule() (publish, expire *time.Time) { return f.PublishAt, f.ExpireAt }
 
 func (f *Form) Contents() string {
 	b := bytes.NewBuffer(nil)
@@ -76,28 +89,63 @@ func (f *Form) Contents() string {
 	return b.String()
 }
 
+func (f *Form) frontMatter() (string, error) {
+	screens := make([]yaml.MapSlice, len(f.Screens))
+	for i, s := range f.Screens {
+		screens[i] = metaMap((*formScreenYAML)(&f.Screens[i]))
+		if len(s.Items) == 0 {
+			continue
+		}
+		items := make([]yaml.MapSlice, len(s.Items))
+		for j := range s.Items {
+			items[j] = metaMap(&s.Items[j])
+		}
+		screens[i] = append(screens[i], yaml.MapItem{Key: "Items", Value: items})
+	}
+	return writeFrontMatter(append(metaMap(f), yaml.MapItem{Key: "Screens", Value: screens}))
+}
+
 func (f *Form) SetContents(contents string) error {
+	var err error
+	if isFrontMatter(contents) {
+		err = f.setFrontMatter(contents)
+	} else {
+		err = f.setRows(contents)
+	}
+	if err != nil {
+		return err
+	}
+	if err := checkStatus(f.Status); err != nil {
+		return err
+	}
+	if err := checkSchedule(f.PublishAt, f.ExpireAt); err != nil {
+		return err
+	}
+	return f.checkConditions()
+}
+
+func (f *Form) setRows(contents string) error {
 	parts := strings.Split(contents, bodySeparator)
-	if err := setMeta(parts[0], f); err != nil {
+	if err := setRows(parts[0], f); err != nil {
 		return err
 	}
 	screenIndex := -1
 	for _, p := range parts[1:] {
-		m := metaRow.FindStringSubmatch(strings.SplitN(p, "\n", 2)[0])
-		if screenIndex < 0 && (len(m) != 3 || m[1] != "Type" || m[2] != "screen") {
+		kind := metaValue(p, "Type")
+		if screenIndex < 0 && kind != "screen" {
 			return ErrContent
 		}
-		switch m[2] {
+		switch kind {
 		case "screen":
 			var s FormScreen
-			if err := setMeta(p, &s); err != nil {
+			if err := setRows(p, &s); err != nil {
 				return err
 			}
 			f.Screens = append(f.Screens, s)
 			screenIndex++
 		default:
 			var i FormInput
-			if err := setMeta(p, &i); err != nil {
+			if err := setRows(p, &i); err != nil {
 				return err
 			}
 			f.Screens[screenIndex].Items = append(f.Screens[screenIndex].Items, i)
@@ -107,36 +155,91 @@ func (f *Form) SetContents(contents string) error {
 	return nil
 }
 
+func (f *Form) setFrontMatter(contents string) error {
+	values, _, err := readFrontMatter(contents)
+	if err != nil {
+		return err
+	}
+	screens, err := popList(values, "Screens")
+	if err != nil {
+		return err
+	}
+	if err := setMetaMap(values, f); err != nil {
+		return err
+	}
+	for _, v := range screens {
+		items, err := popList(v, "Items")
+		if err != nil {
+			return err
+		}
+		var s FormScreen
+		if err := setMetaMap(v, (*formScreenYAML)(&s)); err != nil {
+			return err
+		}
+		s.Items = make([]FormInput, len(items))
+		for i := range items {
+			if err := setMetaMap(items[i], &s.Items[i]); err != nil {
+				return err
+			}
+		}
+		f.Screens = append(f.Screens, s)
+	}
+	return nil
+}
+
 type FormScreen struct {
-	Name  string      `json:"name"`
-	Items []FormInput `json:"items,omitempty"`
+	Name   string      `json:"name"`
+	ShowIf []string    `json:"show_if,omitempty"`
+	Extra  Extra       `json:"extra,omitempty"`
+	Items  []FormInput `json:"items,omitempty"`
 }
 
-func (*FormScreen) order() []string     { return []string{"Type", "Name"} }
-func (*FormScreen) optionals() []string { return nil }
-func (f *FormScreen) pointers() args    { var s string; return args{&s, &f.Name} }
-func (f *FormScreen) values() args      { return args{"screen", f.Name} }
+func (*FormScreen) order() []string     { return []string{"Type", "Name", "ShowIf"} }
+func (*FormScreen) optionals() []string { return []string{"ShowIf"} }
+func (f *FormScreen) pointers() args    { var s string; return args{&s, &f.Name, &f.ShowIf} }
+func (f *FormScreen) extra() *Extra     { return &f.Extra }
+func (f *FormScreen) values() args      { return args{"screen", f.Name, f.ShowIf} }
+
+// formScreenYAML is the metadata of a screen in the front matter, without the type
+type formScreenYAML FormScreen
+
+func (*formScreenYAML) order() []string     { return []string{"Name", "ShowIf"} }
+func (*formScreenYAML) optionals() []string { return []string{"ShowIf"} }
+func (f *formScreenYAML) pointers() args    { return args{&f.Name, &f.ShowIf} }
+func (f *formScreenYAML) extra() *Extra     { return &f.Extra }
+func (f *formScreenYAML) values() args      { return args{f.Name, f.ShowIf} }
 
 type FormInput struct {
-	Type    string   `json:"type"`
-	Name    string   `json:"name,omitempty"`
-	Label   string   `json:"label,omitempty"`
-	Value   []string `json:"value,omitempty"`
-	Options []string `json:"options,omitempty"`
-	Hint    string   `json:"hint,omitempty"`
-	Lines   int      `json:"lines,omitempty"`
+	Type      string   `json:"type"`
+	Name      string   `json:"name,omitempty"`
+	Label     string   `json:"label,omitempty"`
+	Value     []string `json:"value,omitempty"`
+	Options   []string `json:"options,omitempty"`
+	Hint      string   `json:"hint,omitempty"`
+	Lines     int      `json:"lines,omitempty"`
+	Required  bool     `json:"required,omitempty"`
+	Pattern   string   `json:"pattern,omitempty"`
+	Min       string   `json:"min,omitempty"`
+	Max       string   `json:"max,omitempty"`
+	MaxLength int      `json:"max_length,omitempty"`
+	ShowIf    []string `json:"show_if,omitempty"`
+	Extra     Extra    `json:"extra,omitempty"`
 }
 
 func (*FormInput) order() []string {
-	return []string{"Type", "Name", "Label", "Value", "Options", "Hint", "Lines"}
+	return []string{"Type", "Name", "Label", "Value", "Options", "Hint", "Lines", "Required", "Pattern", "Min", "Max", "MaxLength", "ShowIf"}
 }
 func (*FormInput) optionals() []string {
-	return []string{"Value", "Options", "Hint", "Lines"}
+	return []string{"Value", "Options", "Hint", "Lines", "Required", "Pattern", "Min", "Max", "MaxLength", "ShowIf"}
 }
 
 func (f *FormInput) pointers() args {
-	return args{&f.Type, &f.Name, &f.Label, &f.Value, &f.Options, &f.Hint, &f.Lines}
+	return args{&f.Type, &f.Name, &f.Label, &f.Value, &f.Options, &f.Hint, &f.Lines,
+		&f.Required, &f.Pattern, &f.Min, &f.Max, &f.MaxLength, &f.ShowIf}
 }
+func (f *FormInput) extra() *Extra { return &f.Extra }
+
 func (f *FormInput) values() args {
-	return args{f.Type, f.Name, f.Label, f.Value, f.Options, f.Hint, f.Lines}
+	return args{f.Type, f.Name, f.Label, f.Value, f.Options, f.Hint, f.Lines,
+		f.Required, f.Pattern, f.Min, f.Max, f.MaxLength, f.ShowIf}
 }
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"regexp"
 	"strings"
+	"time"
 
 	"github.com/russross/blackfriday"
 )
@@ -11,13 +12,20 @@ import (
 const paragraphSep = "\n\n"
 
 type Item struct {
-	parent   *Difficulty
-	ID       string `json:"id"`
-	Hash     string `json:"hash,omitempty"`
-	Title    string `json:"title"`
-	Body     string `json:"body"`
-	htmlBody string
-	Order    float64 `json:"-"`
+	parent       *Difficulty
+	ID           string     `json:"id"`
+	Hash         string     `json:"hash,omitempty"`
+	Title        string     `json:"title"`
+	Body         string     `json:"body"`
+	Status       string     `json:"status,omitempty"`
+	PublishAt    *time.Time `json:"publish_at,omitempty"`
+	ExpireAt     *time.Time `json:"expire_at,omitempty"`
+	LastReviewed *time.Time `json:"last_reviewed,omitempty"`
+	ReviewEvery  int        `json:"review_every,omitempty"`
+	Tags         []string   `json:"tags,omitempty"`
+	Extra        Extra      `json:"extra,omitempty"`
+	htmlBody     string
+	Order        float64 `json:"-"`
 }
 
 func (i *Item) Resource() Resource {
@@ -59,24 +67,85 @@ func (i *Item) SetPath(filepath string) error {
 	return nil
 }
 
-func (*Item) order() []string     { return []string{"Title", "Order"} }
-func (*Item) optionals() []string { return nil }
-func (i *Item) pointers() args    { return args{&i.Title, &i.Order} }
-func (i *Item) values() args      { return args{i.Title, i.Order} }
+func (*Item) order() []string {
+	return []string{"Title", "Order", "Status", "PublishAt", "ExpireAt", "LastReviewed", "ReviewEvery", "Tags"}
+}
+func (*Item) optionals() []string {
+	return []string{"Status", "PublishAt", "ExpireAt", "LastReviewed", "ReviewEvery", "Tags"}
+}
+func (i *Item) pointers() args {
+	return args{&i.Title, &i.Order, &i.Status, &i.PublishAt, &i.ExpireAt, &i.LastReviewed, &i.ReviewEvery, &i.Tags}
+}
+func (i *Item) extra() *Extra { return &i.Extra }
+func (i *Item) values() args {
+	return args{i.Title, i.Order, i.Status, i.PublishAt, i.ExpireAt, i.LastReviewed, i.ReviewEvery, i.Tags}
+}
+
+func (i *Item) State() string          { return i.Status }
+func (i *Item) SetState(status string) { i.Status = status }
+
+func (i *Item) Schedule() (publish, expire *time.Time) { return i.PublishAt, i.ExpireAt }
+
+func (i *Item) Review() (last *time.Time, every int) { return i.LastReviewed, i.ReviewEvery }
+
+// Refs returns the references to other items in the body
+func (i *Item) Refs() []Ref { return parseRefs(i.Body) }
+
+// Ref returns the reference to the item
+func (i *Item) Ref() Ref {
+	d := i.parent
+	return Ref{Category: d.parent.parent.ID, Subcategory: d.parent.ID, Difficulty: d.ID, Item: i.ID}
+}
 
 func (i *Item) Contents() string {
 	return fmt.Sprint(getMeta(i), bodySeparator, i.Body)
 }
 
+func (i *Item) frontMatter() (string, error) {
+	meta, err := writeFrontMatter(metaMap(i))
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprint(meta, bodySeparator, i.Body), nil
+}
+
 func (i *Item) SetContents(contents string) error {
-	parts := strings.SplitN(strings.Trim(contents, "\n"), bodySeparator, 2)
-	if len(parts) != 2 {
-		return ErrContent
+	contents = strings.Trim(contents, "\n")
+	var body string
+	if isFrontMatter(contents) {
+		values, rest, err := readFrontMatter(contents)
+		if err != nil {
+			return err
+		}
+		if err := setMetaMap(values, i); err != nil {
+			return err
+		}
+		body = rest
+	} else {
+		parts := strings.SplitN(contents, bodySeparator, 2)
+		if len(parts) != 2 {
+			return ErrContent
+		}
+		if err := setRows(parts[0], i); err != nil {
+			return err
+		}
+		body = parts[1]
+	}
+	if err := checkStatus(i.Status); err != nil {
+		return err
+	}
+	if err := checkSchedule(i.PublishAt, i.ExpireAt); err != nil {
+		return err
 	}
-	if err := setMeta(parts[0], i); err != nil {
+	if err := checkReview(i.ReviewEvery); err != nil {
 		return err
 	}
-	i.Body = parts[1]
-	i.htmlBody = string(blackfriday.Run([]byte(i.Body)))
+	i.Body = body
+	i.htmlBody = i.html("", "")
 	return nil
 }
+
+// html renders the body, with the references linking the API path of the items
+func (i *Item) html(prefix, locale string) string {
+	return string(blackfriday.Run([]byte(resolveRefs(i.Body, prefix, locale))))
+}
@@ -8,10 +8,12 @@ import (
 
 type Subcategory struct {
 	parent       *Category
-	ID           string  `json:"id"`
-	Name         string  `json:"name"`
-	Hash         string  `json:"hash"`
-	Order        float64 `json:"-"`
+	ID           string   `json:"id"`
+	Name         string   `json:"name"`
+	Hash         string   `json:"hash"`
+	Order        float64  `json:"-"`
+	Tags         []string `json:"tags,omitempty"`
+	Extra        Extra    `json:"extra,omitempty"`
 	difficulties []*Difficulty
 }
 
@@ -28,16 +30,20 @@ func (s *Subcategory) HasChildren() bool {
 	return len(s.difficulties) != 0
 }
 
-func (s *Subcategory) Tree(html bool) interface{} {
+func (s *Subcategory) Tree(html, all bool) interface{} {
 	var difficulties = make([]interface{}, len(s.difficulties))
 	for i, v := range s.difficulties {
-		difficulties[i] = v.Tree(html)
+		difficulties[i] = v.Tree(html, all)
 	}
-	return map[string]interface{}{
+	var m = map[string]interface{}{
 		"id":           s.ID,
 		"name":         s.Name,
 		"difficulties": difficulties,
 	}
+	if len(s.Tags) != 0 {
+		m["tags"] = s.Tags
+	}
+	return m
 }
 
 func (s *Subcategory) SHA() string {
@@ -56,6 +62,12 @@ func (s *Subcategory) MarshalJSON() ([]byte, error) {
 	if s.Hash != "" {
 		m["hash"] = s.Hash
 	}
+	if len(s.Tags) != 0 {
+		m["tags"] = s.Tags
+	}
+	if len(s.Extra) != 0 {
+		m["extra"] = s.Extra
+	}
 	return json.Marshal(m)
 }
 
@@ -114,13 +126,16 @@ func (s *Subcategory) SetPath(filepath string) error {
 	return nil
 }
 
-func (*Subcategory) order() []string     { return []string{"Name", "Order"} }
-func (*Subcategory) optionals() []string { return nil }
-func (s *Subcategory) pointers() args    { return args{&s.Name, &s.Order} }
-func (s *Subcategory) values() args      { return args{s.Name, s.Order} }
+func (*Subcategory) order() []string     { return []string{"Name", "Order", "Tags"} }
+func (*Subcategory) optionals() []string { return []string{"Tags"} }
+func (s *Subcategory) pointers() args    { return args{&s.Name, &s.Order, &s.Tags} }
+func (s *Subcategory) extra() *Extra     { return &s.Extra }
+func (s *Subcategory) values() args      { return args{s.Name, s.Order, s.Tags} }
 
 func (s *Subcategory) Contents() string { return getMeta(s) }
 
+func (s *Subcategory) frontMatter() (string, error) { return writeFrontMatter(metaMap(s)) }
+
 func (s *Subcategory) SetContents(contents string) error {
 	return setMeta(contents, s)
 }
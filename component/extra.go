@@ -0,0 +1,73 @@
+package component
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	yaml "gopkg.in/yaml.v2"
+)
+
+// Extra contains the metadata unknown to Tent, kept to be written back as it is
+type Extra map[string]interface{}
+
+// extensible is a meta that keeps unknown fields
+type extensible interface {
+	extra() *Extra
+}
+
+func (e *Extra) set(key string, value interface{}) {
+	if *e == nil {
+		*e = make(Extra)
+	}
+	(*e)[key] = value
+}
+
+func (e Extra) keys() []string {
+	var keys = make([]string, 0, len(e))
+	for k := range e {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// rows returns the fields as [Key]: # (value) rows, sorted by key
+func (e Extra) rows() []string {
+	var rows = make([]string, 0, len(e))
+	for _, k := range e.keys() {
+		rows = append(rows, fmt.Sprintf("[%s]: # (%s)", k, extraString(e[k])))
+	}
+	return rows
+}
+
+// mapItems returns the fields as YAML items, sorted by key
+func (e Extra) mapItems() yaml.MapSlice {
+	var items = make(yaml.MapSlice, 0, len(e))
+	for _, k := range e.keys() {
+		items = append(items, yaml.MapItem{Key: k, Value: e[k]})
+	}
+	return items
+}
+
+func extraString(v interface{}) string {
+	switch t := v.(type) {
+	case nil:
+		return ""
+	case []interface{}:
+		list := make([]string, len(t))
+		for i := range t {
+			list[i] = fmt.Sprint(t[i])
+		}
+		return strings.Join(list, ";")
+	}
+	return fmt.Sprint(v)
+}
+
+// extraOf returns the unknown fields of the meta, nil if it doesn't keep them
+func extraOf(m meta) *Extra {
+	if e, ok := m.(extensible); ok {
+		return e.extra()
+	}
+	return nil
+}
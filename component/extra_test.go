@@ -0,0 +1,69 @@
+package component
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestExtra(t *testing.T) {
+	var testCases = []struct {
+		cmp      Component
+		contents string
+		result   string
+		extra    Extra
+	}{
+		{
+			new(Item),
+			"[Order]: # (1)\n[Source]: # (https://example.com)\n[Title]: # (Title)\n\nBody",
+			"[Title]: # (Title)\n[Order]: # (1)\n[Source]: # (https://example.com)\n\nBody",
+			Extra{"Source": "https://example.com"},
+		},
+		{
+			new(Category),
+			"[Name]: # (Name)\n[Icon]: # (shield)\n[Order]: # (1)\n[Color]: # (red)",
+			"[Name]: # (Name)\n[Order]: # (1)\n[Color]: # (red)\n[Icon]: # (shield)",
+			Extra{"Icon": "shield", "Color": "red"},
+		},
+		{
+			new(Item),
+			"---\nTitle: Title\nOrder: 1\nAudience:\n- a\n- b\n---\n\nBody",
+			"[Title]: # (Title)\n[Order]: # (1)\n[Audience]: # (a;b)\n\nBody",
+			Extra{"Audience": []interface{}{"a", "b"}},
+		},
+		{
+			new(Checklist),
+			"[Owner]: # (team)\n\n[NoCheck]: # (false)\n[Text]: # (Check)",
+			"[Owner]: # (team)\n\n[Text]: # (Check)\n[NoCheck]: # (false)",
+			Extra{"Owner": "team"},
+		},
+		{
+			new(Form),
+			"[Name]: # (Form)\n\n[Name]: # (Screen)\n[Type]: # (screen)\n\n[Label]: # (Label)\n[Name]: # (text)\n[Type]: # (text_input)\n[Mask]: # (###)",
+			"[Name]: # (Form)\n\n[Type]: # (screen)\n[Name]: # (Screen)\n\n[Type]: # (text_input)\n[Name]: # (text)\n[Label]: # (Label)\n[Mask]: # (###)",
+			nil,
+		},
+	}
+	for _, tc := range testCases {
+		if err := tc.cmp.SetContents(tc.contents); err != nil {
+			t.Errorf("%q: %s", tc.contents, err)
+			continue
+		}
+		if c := tc.cmp.Contents(); c != tc.result {
+			t.Errorf("expected \n%q, got \n%q", tc.result, c)
+		}
+		if tc.extra == nil {
+			continue
+		}
+		if e := *tc.cmp.(extensible).extra(); !reflect.DeepEqual(e, tc.extra) {
+			t.Errorf("%q: expected extra %v, got %v", tc.contents, tc.extra, e)
+		}
+	}
+	for _, v := range []string{
+		"[Title]: # (Title)\n[Title]: # (Again)\n[Order]: # (1)\n\nBody",
+		"[Order]: # (1)\n\nBody",
+	} {
+		if err := new(Item).SetContents(v); err == nil {
+			t.Errorf("%q: expected error", v)
+		}
+	}
+}
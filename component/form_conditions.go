@@ -0,0 +1,172 @@
+package component
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+var ErrCondition = errors.New("Invalid condition")
+
+// Condition shows an input or a screen if another input has a value, written
+// as name=value, or if it has not, written as name!=value
+type Condition struct {
+	Input string
+	Value string
+	Not   bool
+}
+
+// ParseCondition returns the condition written in the string
+func ParseCondition(s string) (Condition, error) {
+	var c Condition
+	i := strings.Index(s, "=")
+	if i < 0 {
+		return c, ErrCondition
+	}
+	c.Input, c.Value = s[:i], strings.TrimSpace(s[i+1:])
+	if strings.HasSuffix(c.Input, "!") {
+		c.Input, c.Not = c.Input[:len(c.Input)-1], true
+	}
+	if c.Input = strings.TrimSpace(c.Input); c.Input == "" {
+		return c, ErrCondition
+	}
+	return c, nil
+}
+
+func (c Condition) String() string {
+	if c.Not {
+		return c.Input + "!=" + c.Value
+	}
+	return c.Input + "=" + c.Value
+}
+
+// Match returns true if the values of the input satisfy the condition
+func (c Condition) Match(values []string) bool {
+	for _, v := range values {
+		if v == c.Value {
+			return !c.Not
+		}
+	}
+	return c.Not
+}
+
+// schema returns the JSON Schema of the submissions that satisfy the condition
+func (c Condition) schema(input *FormInput) map[string]interface{} {
+	var value interface{} = c.Value
+	switch input.Type {
+	case InputCheckbox:
+		value = c.Value == "true"
+	case InputNumber:
+		if n, err := strconv.ParseFloat(c.Value, 64); err == nil {
+			value = n
+		}
+	}
+	var match = map[string]interface{}{"const": value}
+	if input.Type == InputMultiple {
+		match = map[string]interface{}{"contains": match}
+	}
+	if c.Not {
+		return map[string]interface{}{
+			"properties": map[string]interface{}{c.Input: map[string]interface{}{"not": match}},
+		}
+	}
+	return map[string]interface{}{
+		"properties": map[string]interface{}{c.Input: match},
+		"required":   []string{c.Input},
+	}
+}
+
+// conditionValue returns true if the value is allowed by the input
+func (f *FormInput) conditionValue(v string) bool {
+	switch f.Type {
+	case InputCheckbox:
+		return v == "true" || v == "false"
+	case InputSingle, InputMultiple:
+		for _, o := range f.Options {
+			if o == v {
+				return true
+			}
+		}
+		return false
+	}
+	return true
+}
+
+// checkConditions returns an error if a condition is invalid, refers to an
+// unknown input or option, or if the conditions depend on each other
+func (f *Form) checkConditions() error {
+	var (
+		inputs = make(map[string]*FormInput)
+		names  []string
+		deps   = make(map[string][]string)
+	)
+	for _, s := range f.Screens {
+		for i := range s.Items {
+			inputs[s.Items[i].Name] = &s.Items[i]
+			names = append(names, s.Items[i].Name)
+		}
+	}
+	refs := func(owner string, showIf []string) ([]string, error) {
+		var list []string
+		for _, s := range showIf {
+			c, err := ParseCondition(s)
+			if err != nil {
+				return nil, fmt.Errorf("%s: invalid condition %q", owner, s)
+			}
+			input, ok := inputs[c.Input]
+			if !ok {
+				return nil, fmt.Errorf("%s: unknown input %s", owner, c.Input)
+			}
+			if !input.conditionValue(c.Value) {
+				return nil, fmt.Errorf("%s: unknown value %q of %s", owner, c.Value, c.Input)
+			}
+			list = append(list, c.Input)
+		}
+		return list, nil
+	}
+	for _, s := range f.Screens {
+		screen, err := refs("Screen "+s.Name, s.ShowIf)
+		if err != nil {
+			return err
+		}
+		for _, i := range s.Items {
+			list, err := refs("Input "+i.Name, i.ShowIf)
+			if err != nil {
+				return err
+			}
+			deps[i.Name] = append(deps[i.Name], append(list, screen...)...)
+		}
+	}
+	const (
+		visiting = 1
+		visited  = 2
+	)
+	var (
+		state = make(map[string]int)
+		visit func(name string) string
+	)
+	// visit returns an input of a cycle, if any
+	visit = func(name string) string {
+		switch state[name] {
+		case visiting:
+			return name
+		case visited:
+			return ""
+		}
+		state[name] = visiting
+		for _, d := range deps[name] {
+			if n := visit(d); n != "" {
+				return n
+			}
+		}
+		state[name] = visited
+		return ""
+	}
+	for _, n := range names {
+		if c := visit(n); c != "" {
+			return fmt.Errorf("Input %s: conditions cycle", c)
+		}
+	}
+	return nil
+}
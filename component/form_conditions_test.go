@@ -0,0 +1,83 @@
+package component
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+func TestParseCondition(t *testing.T) {
+	var testCases = []struct {
+		s         string
+		condition Condition
+		err       error
+	}{
+		{"travelling=yes", Condition{Input: "travelling", Value: "yes"}, nil},
+		{"country != none", Condition{Input: "country", Value: "none", Not: true}, nil},
+		{"name=", Condition{Input: "name"}, nil},
+		{"travelling", Condition{}, ErrCondition},
+		{"=yes", Condition{}, ErrCondition},
+		{"!=yes", Condition{}, ErrCondition},
+	}
+	for _, tc := range testCases {
+		c, err := ParseCondition(tc.s)
+		if err != tc.err {
+			t.Errorf("%s: expected %v, got %v", tc.s, tc.err, err)
+			continue
+		}
+		if err == nil && c != tc.condition {
+			t.Errorf("%s: expected %v, got %v", tc.s, tc.condition, c)
+		}
+	}
+	c := Condition{Input: "tags", Value: "a", Not: true}
+	if c.String() != "tags!=a" || c.Match([]string{"b", "a"}) || !c.Match(nil) {
+		t.Errorf("unexpected match of %s", c)
+	}
+}
+
+func TestFormConditions(t *testing.T) {
+	const form = "[Name]: # (Report)\n\n" +
+		"[Type]: # (screen)\n[Name]: # (Travel)\n\n" +
+		"[Type]: # (single_choice)\n[Name]: # (travelling)\n[Label]: # (Travelling?)\n[Options]: # (yes;no)\n\n" +
+		"[Type]: # (text_input)\n[Name]: # (country)\n[Label]: # (Which country)\n[Required]: # (true)\n[ShowIf]: # (travelling=yes)\n\n" +
+		"[Type]: # (screen)\n[Name]: # (Details)\n[ShowIf]: # (%s)\n\n" +
+		"[Type]: # (text_area)\n[Name]: # (details)\n[Label]: # (Details)"
+	var testCases = []struct {
+		showIf string
+		valid  bool
+	}{
+		{"country!=", true},
+		{"travelling=maybe", false},
+		{"unknown=yes", false},
+		{"travelling", false},
+		{"details=x", false},
+	}
+	for _, tc := range testCases {
+		var f Form
+		if err := f.SetContents(fmt.Sprintf(form, tc.showIf)); (err == nil) != tc.valid {
+			t.Errorf("%s: unexpected error %v", tc.showIf, err)
+		}
+	}
+	var f Form
+	if err := f.SetContents(fmt.Sprintf(form, "country!=")); err != nil {
+		t.Fatal(err)
+	}
+	if s := f.Contents(); s != fmt.Sprintf(form, "country!=") {
+		t.Errorf("unexpected contents %q", s)
+	}
+	f.Screens[0].Items[0].ShowIf = []string{"country=x"}
+	if err := f.Validate(); err == nil {
+		t.Error("expected cycle")
+	}
+	f.Screens[0].Items[0].ShowIf = nil
+	expected := []interface{}{map[string]interface{}{
+		"if": map[string]interface{}{"allOf": []interface{}{map[string]interface{}{
+			"properties": map[string]interface{}{"travelling": map[string]interface{}{"const": "yes"}},
+			"required":   []string{"travelling"},
+		}}},
+		"then": map[string]interface{}{"required": []string{"country"}},
+	}}
+	if s := f.Schema(); !reflect.DeepEqual(s["allOf"], expected) || len(s["required"].([]string)) != 0 {
+		t.Errorf("unexpected schema %v", s)
+	}
+}
@@ -0,0 +1,238 @@
+package component
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"time"
+)
+
+// Types of form inputs
+const (
+	InputText      = "text_input"
+	InputMultiline = "text_area"
+	InputNumber    = "number"
+	InputDate      = "date"
+	InputSingle    = "single_choice"
+	InputMultiple  = "multiple_choice"
+	InputCheckbox  = "checkbox"
+)
+
+// inputTypes are the known input types
+var inputTypes = map[string]bool{
+	InputText: true, InputMultiline: true, InputNumber: true, InputDate: true,
+	InputSingle: true, InputMultiple: true, InputCheckbox: true,
+}
+
+// jsonSchema is the version of the JSON Schema returned by Schema
+const jsonSchema = "http://json-schema.org/draft-07/schema#"
+
+func inputError(name, format string, args ...interface{}) error {
+	return fmt.Errorf("Input %s: %s", name, fmt.Sprintf(format, args...))
+}
+
+// isText returns true if the input value is free text
+func (f *FormInput) isText() bool { return f.Type == InputText || f.Type == InputMultiline }
+
+// isChoice returns true if the input value is one or more of the options
+func (f *FormInput) isChoice() bool { return f.Type == InputSingle || f.Type == InputMultiple }
+
+// bound parses a limit of the input: a number for numbers, a date for dates and
+// a number of options for multiple choices
+func (f *FormInput) bound(v string) (float64, error) {
+	switch f.Type {
+	case InputNumber:
+		return strconv.ParseFloat(v, 64)
+	case InputDate:
+		t, err := time.Parse(dateFormat, v)
+		if err != nil {
+			return 0, err
+		}
+		return float64(t.Unix()), nil
+	case InputMultiple:
+		n, err := strconv.Atoi(v)
+		if err == nil && n < 0 {
+			err = ErrContent
+		}
+		return float64(n), err
+	}
+	return 0, ErrContent
+}
+
+// Validate returns an error if the input has an unknown type or rules that do
+// not apply to it
+func (f *FormInput) Validate() error {
+	if f.Name == "" {
+		return fmt.Errorf("Missing %v", "Name")
+	}
+	if !inputTypes[f.Type] {
+		return inputError(f.Name, "unknown type %q", f.Type)
+	}
+	if f.isChoice() && len(f.Options) == 0 {
+		return inputError(f.Name, "missing options")
+	}
+	if f.Pattern != "" {
+		if !f.isText() {
+			return inputError(f.Name, "pattern not allowed for %s", f.Type)
+		}
+		if _, err := regexp.Compile(f.Pattern); err != nil {
+			return inputError(f.Name, "invalid pattern %q", f.Pattern)
+		}
+	}
+	if f.MaxLength != 0 && (!f.isText() || f.MaxLength < 0) {
+		return inputError(f.Name, "invalid max length %d", f.MaxLength)
+	}
+	var limits [2]*float64
+	for i, v := range []string{f.Min, f.Max} {
+		if v == "" {
+			continue
+		}
+		n, err := f.bound(v)
+		if err != nil {
+			return inputError(f.Name, "invalid limit %q for %s", v, f.Type)
+		}
+		limits[i] = &n
+	}
+	if limits[0] != nil && limits[1] != nil && *limits[0] > *limits[1] {
+		return inputError(f.Name, "min greater than max")
+	}
+	return nil
+}
+
+// Validate returns an error if an input is invalid, its name is not unique or
+// the conditions are not valid
+func (f *Form) Validate() error {
+	var names = make(map[string]bool)
+	for _, s := range f.Screens {
+		for i := range s.Items {
+			input := &s.Items[i]
+			if err := input.Validate(); err != nil {
+				return err
+			}
+			if names[input.Name] {
+				return fmt.Errorf("Duplicate input %s", input.Name)
+			}
+			names[input.Name] = true
+		}
+	}
+	return f.checkConditions()
+}
+
+// Schema returns the JSON Schema of the input value
+func (f *FormInput) Schema() map[string]interface{} {
+	var s = map[string]interface{}{"title": f.Label}
+	if f.Hint != "" {
+		s["description"] = f.Hint
+	}
+	switch f.Type {
+	case InputText, InputMultiline:
+		s["type"] = "string"
+		if f.Pattern != "" {
+			s["pattern"] = f.Pattern
+		}
+		if f.MaxLength != 0 {
+			s["maxLength"] = f.MaxLength
+		}
+	case InputNumber:
+		s["type"] = "number"
+		if n, err := f.bound(f.Min); err == nil {
+			s["minimum"] = n
+		}
+		if n, err := f.bound(f.Max); err == nil {
+			s["maximum"] = n
+		}
+	case InputDate:
+		s["type"], s["format"] = "string", "date"
+		if f.Min != "" {
+			s["formatMinimum"] = f.Min
+		}
+		if f.Max != "" {
+			s["formatMaximum"] = f.Max
+		}
+	case InputSingle:
+		s["type"], s["enum"] = "string", f.Options
+	case InputMultiple:
+		s["type"], s["uniqueItems"] = "array", true
+		s["items"] = map[string]interface{}{"type": "string", "enum": f.Options}
+		if n, err := f.bound(f.Min); err == nil {
+			s["minItems"] = int(n)
+		}
+		if n, err := f.bound(f.Max); err == nil {
+			s["maxItems"] = int(n)
+		}
+	case InputCheckbox:
+		s["type"] = "boolean"
+		if f.Required {
+			s["const"] = true
+		}
+	}
+	if len(f.Value) != 0 && f.Value[0] != "" {
+		switch f.Type {
+		case InputMultiple:
+			s["default"] = f.Value
+		case InputNumber:
+			if n, err := strconv.ParseFloat(f.Value[0], 64); err == nil {
+				s["default"] = n
+			}
+		case InputCheckbox:
+			s["default"] = f.Value[0] == "true"
+		default:
+			s["default"] = f.Value[0]
+		}
+	}
+	return s
+}
+
+// Schema returns the JSON Schema of the form submissions, an object with a
+// property for each input: the required ones with conditions are required only
+// if the conditions are satisfied
+func (f *Form) Schema() map[string]interface{} {
+	var (
+		properties = make(map[string]interface{})
+		required   = make([]string, 0)
+		inputs     = make(map[string]*FormInput)
+		rules      []interface{}
+	)
+	for _, s := range f.Screens {
+		for i := range s.Items {
+			inputs[s.Items[i].Name] = &s.Items[i]
+		}
+	}
+	for _, s := range f.Screens {
+		for i := range s.Items {
+			input := &s.Items[i]
+			properties[input.Name] = input.Schema()
+			if !input.Required {
+				continue
+			}
+			var conditions []interface{}
+			for _, v := range append(append([]string{}, s.ShowIf...), input.ShowIf...) {
+				c, err := ParseCondition(v)
+				if err != nil || inputs[c.Input] == nil {
+					continue
+				}
+				conditions = append(conditions, c.schema(inputs[c.Input]))
+			}
+			if len(conditions) == 0 {
+				required = append(required, input.Name)
+				continue
+			}
+			rules = append(rules, map[string]interface{}{
+				"if":   map[string]interface{}{"allOf": conditions},
+				"then": map[string]interface{}{"required": []string{input.Name}},
+			})
+		}
+	}
+	var schema = map[string]interface{}{
+		"$schema":              jsonSchema,
+		"title":                f.Name,
+		"type":                 "object",
+		"properties":           properties,
+		"required":             required,
+		"additionalProperties": false,
+	}
+	if len(rules) != 0 {
+		schema["allOf"] = rules
+	}
+	return schema
+}
@@ -0,0 +1,76 @@
+package component
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestFormInputValidate(t *testing.T) {
+	var testCases = []struct {
+		input FormInput
+		valid bool
+	}{
+		{FormInput{Type: InputText, Name: "a", Pattern: `^\d+$`, MaxLength: 10}, true},
+		{FormInput{Type: "text", Name: "a"}, false},
+		{FormInput{Type: InputText}, false},
+		{FormInput{Type: InputText, Name: "a", Pattern: `(`}, false},
+		{FormInput{Type: InputNumber, Name: "a", Pattern: `\d`}, false},
+		{FormInput{Type: InputNumber, Name: "a", MaxLength: 2}, false},
+		{FormInput{Type: InputNumber, Name: "a", Min: "0", Max: "10.5"}, true},
+		{FormInput{Type: InputNumber, Name: "a", Min: "10", Max: "1"}, false},
+		{FormInput{Type: InputDate, Name: "a", Min: "2019-01-01"}, true},
+		{FormInput{Type: InputDate, Name: "a", Max: "tomorrow"}, false},
+		{FormInput{Type: InputSingle, Name: "a"}, false},
+		{FormInput{Type: InputSingle, Name: "a", Options: []string{"x"}, Min: "1"}, false},
+		{FormInput{Type: InputMultiple, Name: "a", Options: []string{"x", "y"}, Min: "1", Max: "2"}, true},
+		{FormInput{Type: InputMultiple, Name: "a", Options: []string{"x", "y"}, Min: "-1"}, false},
+		{FormInput{Type: InputCheckbox, Name: "a", Required: true}, true},
+	}
+	for _, tc := range testCases {
+		if err := tc.input.Validate(); (err == nil) != tc.valid {
+			t.Errorf("%+v: unexpected error %v", tc.input, err)
+		}
+	}
+	f := Form{Screens: []FormScreen{
+		{Name: "1", Items: []FormInput{{Type: InputText, Name: "a"}}},
+		{Name: "2", Items: []FormInput{{Type: InputNumber, Name: "a"}}},
+	}}
+	if err := f.Validate(); err == nil {
+		t.Error("expected duplicate input")
+	}
+}
+
+func TestFormSchema(t *testing.T) {
+	var f Form
+	if err := f.SetContents("[Name]: # (Report)\n\n[Type]: # (screen)\n[Name]: # (Screen)\n\n" +
+		"[Type]: # (text_input)\n[Name]: # (code)\n[Label]: # (Code)\n[Required]: # (true)\n[Pattern]: # ([A-Z]+)\n[MaxLength]: # (5)\n\n" +
+		"[Type]: # (multiple_choice)\n[Name]: # (tags)\n[Label]: # (Tags)\n[Options]: # (a;b)\n[Max]: # (1)"); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	if i := f.Screens[0].Items[0]; !i.Required || i.MaxLength != 5 || i.Pattern != "[A-Z]+" {
+		t.Errorf("unexpected input %+v", i)
+	}
+	expected := map[string]interface{}{
+		"$schema":              jsonSchema,
+		"title":                "Report",
+		"type":                 "object",
+		"required":             []string{"code"},
+		"additionalProperties": false,
+		"properties": map[string]interface{}{
+			"code": map[string]interface{}{"title": "Code", "type": "string", "pattern": "[A-Z]+", "maxLength": 5},
+			"tags": map[string]interface{}{
+				"title": "Tags", "type": "array", "uniqueItems": true, "maxItems": 1,
+				"items": map[string]interface{}{"type": "string", "enum": []string{"a", "b"}},
+			},
+		},
+	}
+	if s := f.Schema(); !reflect.DeepEqual(s, expected) {
+		t.Errorf("expected %v, got %v", expected, s)
+	}
+	if s := getMeta(&f.Screens[0].Items[0]); s != "[Type]: # (text_input)\n[Name]: # (code)\n[Label]: # (Code)\n[Required]: # (true)\n[Pattern]: # ([A-Z]+)\n[MaxLength]: # (5)" {
+		t.Errorf("unexpected rows %q", s)
+	}
+}
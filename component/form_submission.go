@@ -0,0 +1,173 @@
+package component
+
+import (
+	"regexp"
+	"strconv"
+	"unicode/utf8"
+)
+
+// Errors of the submitted values
+const (
+	valueRequired = "required"
+	valueInvalid  = "invalid value"
+	valueUnknown  = "unknown input"
+	valueOption   = "unknown option"
+	valueFormat   = "invalid format"
+	valueLength   = "too long"
+	valueMin      = "below minimum"
+	valueMax      = "above maximum"
+)
+
+// submitted returns the value of an input as a list, false if its JSON type is not valid
+func submitted(v interface{}) ([]string, bool) {
+	switch t := v.(type) {
+	case nil:
+		return nil, true
+	case string:
+		if t == "" {
+			return nil, true
+		}
+		return []string{t}, true
+	case float64:
+		return []string{strconv.FormatFloat(t, 'f', -1, 64)}, true
+	case bool:
+		return []string{strconv.FormatBool(t)}, true
+	case []interface{}:
+		var list = make([]string, 0, len(t))
+		for _, e := range t {
+			s, ok := e.(string)
+			if !ok {
+				return nil, false
+			}
+			list = append(list, s)
+		}
+		return list, true
+	}
+	return nil, false
+}
+
+// check returns the error of the values of the input, an empty string if valid
+func (f *FormInput) check(values []string) string {
+	if len(values) == 0 || f.Type == InputCheckbox && values[0] == "false" {
+		if f.Required {
+			return valueRequired
+		}
+		return ""
+	}
+	if len(values) > 1 && f.Type != InputMultiple {
+		return valueInvalid
+	}
+	switch f.Type {
+	case InputText, InputMultiline:
+		if f.MaxLength != 0 && utf8.RuneCountInString(values[0]) > f.MaxLength {
+			return valueLength
+		}
+		if f.Pattern == "" {
+			return ""
+		}
+		if p, err := regexp.Compile(f.Pattern); err != nil || !p.MatchString(values[0]) {
+			return valueFormat
+		}
+		return ""
+	case InputCheckbox:
+		if values[0] != "true" {
+			return valueInvalid
+		}
+		return ""
+	case InputSingle, InputMultiple:
+		var seen = make(map[string]bool)
+		for _, v := range values {
+			if seen[v] {
+				return valueInvalid
+			}
+			seen[v] = true
+			if !f.conditionValue(v) {
+				return valueOption
+			}
+		}
+		if f.Type == InputSingle {
+			return ""
+		}
+		return f.checkBounds(float64(len(values)))
+	}
+	n, err := f.bound(values[0])
+	if err != nil {
+		return valueInvalid
+	}
+	return f.checkBounds(n)
+}
+
+// checkBounds returns the error of a value outside Min and Max
+func (f *FormInput) checkBounds(n float64) string {
+	if min, err := f.bound(f.Min); err == nil && n < min {
+		return valueMin
+	}
+	if max, err := f.bound(f.Max); err == nil && n > max {
+		return valueMax
+	}
+	return ""
+}
+
+// Check validates a submission, with the values of the inputs by name, and
+// returns the errors by input: inputs hidden by their conditions are ignored
+func (f *Form) Check(submission map[string]interface{}) map[string]string {
+	var (
+		errs    = make(map[string]string)
+		inputs  = make(map[string]*FormInput)
+		screens = make(map[string]*FormScreen)
+		values  = make(map[string][]string)
+		visible = make(map[string]*bool)
+	)
+	for i := range f.Screens {
+		s := &f.Screens[i]
+		for j := range s.Items {
+			inputs[s.Items[j].Name], screens[s.Items[j].Name] = &s.Items[j], s
+		}
+	}
+	for name, v := range submission {
+		if inputs[name] == nil {
+			errs[name] = valueUnknown
+			continue
+		}
+		list, ok := submitted(v)
+		if !ok {
+			errs[name] = valueInvalid
+			continue
+		}
+		values[name] = list
+	}
+	// isVisible returns true if the conditions of the input and its screen are
+	// satisfied, using the values of the visible inputs only
+	var isVisible func(name string) bool
+	isVisible = func(name string) bool {
+		if v := visible[name]; v != nil {
+			return *v
+		}
+		var result = true
+		for _, s := range append(append([]string{}, screens[name].ShowIf...), inputs[name].ShowIf...) {
+			c, err := ParseCondition(s)
+			if err != nil || inputs[c.Input] == nil {
+				continue
+			}
+			var v []string
+			if isVisible(c.Input) {
+				v = values[c.Input]
+			}
+			if !c.Match(v) {
+				result = false
+				break
+			}
+		}
+		visible[name] = &result
+		return result
+	}
+	for name, input := range inputs {
+		if _, ok := errs[name]; ok || !isVisible(name) {
+			continue
+		}
+		if err := input.check(values[name]); err != "" {
+			errs[name] = err
+		}
+	}
+	return errs
+}
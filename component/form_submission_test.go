@@ -0,0 +1,57 @@
+package component
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestFormCheck(t *testing.T) {
+	f := Form{Screens: []FormScreen{
+		{Name: "Report", Items: []FormInput{
+			{Type: InputText, Name: "code", Required: true, Pattern: `^[A-Z]+$`, MaxLength: 4},
+			{Type: InputNumber, Name: "people", Min: "1", Max: "10"},
+			{Type: InputDate, Name: "when", Max: "2019-12-31"},
+			{Type: InputMultiple, Name: "tags", Options: []string{"a", "b", "c"}, Max: "2"},
+			{Type: InputCheckbox, Name: "consent", Required: true},
+		}},
+		{Name: "Travel", Items: []FormInput{
+			{Type: InputSingle, Name: "travelling", Options: []string{"yes", "no"}},
+			{Type: InputText, Name: "country", Required: true, ShowIf: []string{"travelling=yes"}},
+		}},
+	}}
+	if err := f.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	var testCases = []struct {
+		submission string
+		errors     map[string]string
+	}{
+		{
+			`{"code": "ABC", "people": 3, "when": "2019-01-01", "tags": ["a", "c"], "consent": true, "travelling": "no"}`,
+			map[string]string{},
+		},
+		{
+			`{"code": "ABCDE", "people": 0, "when": "2020-01-01", "tags": ["a", "b", "c"], "consent": false}`,
+			map[string]string{"code": valueLength, "people": valueMin, "when": valueMax, "tags": valueMax, "consent": valueRequired},
+		},
+		{
+			`{"code": "abc", "people": "many", "when": "tomorrow", "tags": ["d"], "consent": true, "travelling": "yes", "other": 1}`,
+			map[string]string{"code": valueFormat, "people": valueInvalid, "when": valueInvalid, "tags": valueOption,
+				"country": valueRequired, "other": valueUnknown},
+		},
+		{
+			`{"code": ["A", "B"], "tags": [1], "consent": "yes", "travelling": "yes", "country": "Italy"}`,
+			map[string]string{"code": valueInvalid, "tags": valueInvalid, "consent": valueInvalid},
+		},
+	}
+	for _, tc := range testCases {
+		var submission map[string]interface{}
+		if err := json.Unmarshal([]byte(tc.submission), &submission); err != nil {
+			t.Fatal(err)
+		}
+		if errs := f.Check(submission); !reflect.DeepEqual(errs, tc.errors) {
+			t.Errorf("%s: expected %v, got %v", tc.submission, tc.errors, errs)
+		}
+	}
+}
@@ -0,0 +1,215 @@
+package component
+
+import (
+	"bytes"
+	"fmt"
+	"html"
+	"io"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	xhtml "golang.org/x/net/html"
+)
+
+// GlossaryType is the built-in type of the glossary, a term for each file of
+// glossary_xx, with the definition as body
+var GlossaryType = Profile{
+	Name: "glossary",
+	Root: "glossary",
+	Levels: []Level{
+		{Name: "term", Fields: []string{"Term", "Synonyms"}, Optionals: []string{"Synonyms"}},
+	},
+}
+
+func init() {
+	if err := Register(GlossaryType); err != nil {
+		panic(err)
+	}
+}
+
+// glossaryURL is the API path of a term, with its locale
+const glossaryURL = "%s/api/glossary/%s?locale=%s"
+
+// Term is an entry of the glossary
+type Term struct {
+	ID         string   `json:"id"`
+	Term       string   `json:"term"`
+	Synonyms   []string `json:"synonyms,omitempty"`
+	Definition string   `json:"definition"`
+}
+
+// Glossary returns the terms of the glossary nodes, sorted
+func Glossary(nodes []*Node) []Term {
+	var terms = make([]Term, 0, len(nodes))
+	for _, n := range nodes {
+		t := Term{ID: n.ID, Term: n.Get("Term"), Definition: n.Body}
+		for _, s := range strings.Split(n.Get("Synonyms"), ";") {
+			if s = strings.TrimSpace(s); s != "" {
+				t.Synonyms = append(t.Synonyms, s)
+			}
+		}
+		terms = append(terms, t)
+	}
+	sort.Slice(terms, func(i, j int) bool {
+		return strings.ToLower(terms[i].Term) < strings.ToLower(terms[j].Term)
+	})
+	return terms
+}
+
+// LinkGlossary links the glossary terms of the locale in the HTML body of the
+// items, under the prefix of the server
+func LinkGlossary(categories []*Category, terms []Term, prefix, locale string) {
+	if len(terms) == 0 {
+		return
+	}
+	l := NewLinker(terms, prefix, locale)
+	for _, cat := range categories {
+		for _, sub := range cat.subcategories {
+			for _, dif := range sub.difficulties {
+				for _, item := range dif.items {
+					item.htmlBody = l.Link(item.htmlBody)
+				}
+			}
+		}
+	}
+}
+
+// noLinks are the elements whose text is never linked
+var noLinks = map[string]bool{"a": true, "code": true, "pre": true, "h1": true, "h2": true, "h3": true}
+
+// Linker links the terms of a glossary in HTML, with their patterns compiled once
+type Linker struct {
+	prefix string
+	locale string
+	words  []termPattern
+}
+
+// NewLinker returns a Linker for the terms, and their synonyms, of the locale,
+// linking their API path under the prefix of the server
+func NewLinker(terms []Term, prefix, locale string) *Linker {
+	l := Linker{prefix: prefix, locale: locale}
+	for i := range terms {
+		for _, w := range append([]string{terms[i].Term}, terms[i].Synonyms...) {
+			if w == "" {
+				continue
+			}
+			w = html.EscapeString(w)
+			l.words = append(l.words, termPattern{w, wordPattern(w), &terms[i]})
+		}
+	}
+	sort.SliceStable(l.words, func(i, j int) bool {
+		return len(l.words[i].word) > len(l.words[j].word)
+	})
+	return &l
+}
+
+// wordPattern matches the word, case insensitive, where it starts or ends with
+// a letter, digit or underscore it must not be preceded or followed by another
+func wordPattern(w string) *regexp.Regexp {
+	var before, after string
+	if r, _ := utf8.DecodeRuneInString(w); isWordRune(r) {
+		before = `(?:^|[^\pL\pN_])`
+	}
+	if r, _ := utf8.DecodeLastRuneInString(w); isWordRune(r) {
+		after = `(?:$|[^\pL\pN_])`
+	}
+	return regexp.MustCompile(`(?i)` + before + `(` + regexp.QuoteMeta(w) + `)` + after)
+}
+
+func isWordRune(r rune) bool { return r == '_' || unicode.IsLetter(r) || unicode.IsNumber(r) }
+
+// Link wraps the first occurrence of each term, or one of its synonyms,
+// in the text of the HTML with a link to its definition
+func (l *Linker) Link(src string) string {
+	var (
+		b      = bytes.NewBuffer(nil)
+		z      = xhtml.NewTokenizer(strings.NewReader(src))
+		linked = make(map[*Term]bool)
+		skip   = 0
+	)
+	for {
+		tt := z.Next()
+		if tt == xhtml.ErrorToken {
+			if z.Err() != io.EOF {
+				return src
+			}
+			break
+		}
+		raw := string(z.Raw())
+		switch tt {
+		case xhtml.StartTagToken, xhtml.EndTagToken:
+			name, _ := z.TagName()
+			if noLinks[string(name)] {
+				if tt == xhtml.StartTagToken {
+					skip++
+				} else if skip > 0 {
+					skip--
+				}
+			}
+		case xhtml.TextToken:
+			if skip == 0 {
+				raw = l.linkText(raw, linked)
+			}
+		}
+		b.WriteString(raw)
+	}
+	return b.String()
+}
+
+// termPattern matches a term, or one of its synonyms, in escaped text
+type termPattern struct {
+	word    string
+	pattern *regexp.Regexp
+	term    *Term
+}
+
+// linkText links the first occurrence of the terms not linked yet in the text,
+// without overlapping the links already added
+func (l *Linker) linkText(text string, linked map[*Term]bool) string {
+	type match struct {
+		start, end int
+		term       *Term
+	}
+	var matches []match
+	for _, w := range l.words {
+		if linked[w.term] {
+			continue
+		}
+		for _, loc := range w.pattern.FindAllStringSubmatchIndex(text, -1) {
+			loc = loc[2:4]
+			overlaps := false
+			for _, m := range matches {
+				if loc[0] < m.end && m.start < loc[1] {
+					overlaps = true
+					break
+				}
+			}
+			if !overlaps {
+				matches = append(matches, match{loc[0], loc[1], w.term})
+				linked[w.term] = true
+				break
+			}
+		}
+	}
+	if len(matches) == 0 {
+		return text
+	}
+	sort.Slice(matches, func(i, j int) bool { return matches[i].start < matches[j].start })
+	var (
+		b    = bytes.NewBuffer(nil)
+		last = 0
+	)
+	for _, m := range matches {
+		b.WriteString(text[last:m.start])
+		href := fmt.Sprintf(glossaryURL, l.prefix, url.PathEscape(m.term.ID), url.QueryEscape(l.locale))
+		fmt.Fprintf(b, `<a class="glossary" href="%s" title="%s">%s</a>`,
+			html.EscapeString(href), html.EscapeString(m.term.Term), text[m.start:m.end])
+		last = m.end
+	}
+	b.WriteString(text[last:])
+	return b.String()
+}
@@ -0,0 +1,73 @@
+package component
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestGlossary(t *testing.T) {
+	n, err := GlossaryType.NewNode("en", "vpn")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := n.SetContents("[Term]: # (VPN)\n[Synonyms]: # (virtual private network; tunnel)\n\nA private network."); err != nil {
+		t.Fatal(err)
+	}
+	tor, _ := GlossaryType.NewNode("en", "tor")
+	tor.Set("Term", "Tor")
+	expected := []Term{
+		{ID: "tor", Term: "Tor"},
+		{ID: "vpn", Term: "VPN", Synonyms: []string{"virtual private network", "tunnel"}, Definition: "A private network."},
+	}
+	if terms := Glossary([]*Node{n, tor}); !reflect.DeepEqual(terms, expected) {
+		t.Errorf("expected %v, got %v", expected, terms)
+	}
+	if p := TypeOf("glossary_en/vpn.md"); p == nil || p.Name != GlossaryType.Name {
+		t.Errorf("unexpected type %v", p)
+	}
+}
+
+func TestLinkTerms(t *testing.T) {
+	terms := []Term{
+		{ID: "vpn", Term: "VPN", Synonyms: []string{"virtual private network"}},
+		{ID: "tor", Term: "Tor"},
+		{ID: "r&d", Term: "R&D"},
+		{ID: `x"><script>`, Term: "C++"},
+		{ID: "onion", Term: ".onion"},
+	}
+	var testCases = []struct {
+		src, expected string
+	}{
+		{
+			"<p>Use a VPN, a vpn is safe.</p>",
+			`<p>Use a <a class="glossary" href="/api/glossary/vpn?locale=en" title="VPN">VPN</a>, a vpn is safe.</p>`,
+		},
+		{
+			"<p>A Virtual Private Network or Tor.</p>",
+			`<p>A <a class="glossary" href="/api/glossary/vpn?locale=en" title="VPN">Virtual Private Network</a> or <a class="glossary" href="/api/glossary/tor?locale=en" title="Tor">Tor</a>.</p>`,
+		},
+		{
+			`<h2>VPN</h2><p><a href="x">Tor</a> <code>tor</code> and tor</p>`,
+			`<h2>VPN</h2><p><a href="x">Tor</a> <code>tor</code> and <a class="glossary" href="/api/glossary/tor?locale=en" title="Tor">tor</a></p>`,
+		},
+		{
+			"<p>Storage and R&amp;D</p>",
+			`<p>Storage and <a class="glossary" href="/api/glossary/r&amp;d?locale=en" title="R&amp;D">R&amp;D</a></p>`,
+		},
+		{
+			"<p>Not foo.onions, but C++ and .onion.</p>",
+			`<p>Not foo.onions, but <a class="glossary" href="/api/glossary/x%22%3E%3Cscript%3E?locale=en" title="C++">C++</a> and <a class="glossary" href="/api/glossary/onion?locale=en" title=".onion">.onion</a>.</p>`,
+		},
+	}
+	l := NewLinker(terms, "", "en")
+	for _, tc := range testCases {
+		if s := l.Link(tc.src); s != tc.expected {
+			t.Errorf("expected %s, got %s", tc.expected, s)
+		}
+	}
+	l = NewLinker(terms, "/tent", "it")
+	if s := l.Link("<p>Tor</p>"); !strings.Contains(s, `href="/tent/api/glossary/tor?locale=it"`) {
+		t.Errorf("unexpected link in %s", s)
+	}
+}
@@ -6,8 +6,15 @@ import (
 	"regexp"
 	"strconv"
 	"strings"
+	"time"
 )
 
+// dateFormat is used for dates without time
+const dateFormat = "2006-01-02"
+
+// dateZone marks the times parsed from a date, so they are written back as dates
+var dateZone = time.FixedZone("UTC", 0)
+
 type meta interface {
 	order() []string
 	optionals() []string
@@ -19,38 +26,76 @@ var metaRow = regexp.MustCompile(`\[([a-zA-Z]+)\]: # \((.*)\)`)
 
 type args []interface{}
 
+// setMeta parses the metadata, as rows or as YAML front matter
 func setMeta(meta string, m meta) error {
-	order, pointers, optionals := m.order(), m.pointers(), m.optionals()
-	rows := strings.Split(meta, "\n")
-	if len(rows) < len(order)-len(optionals) {
-		return fmt.Errorf("Expected %v-%v, got %v", len(order)-len(optionals), len(order), len(rows))
+	if isFrontMatter(meta) {
+		values, _, err := readFrontMatter(meta)
+		if err != nil {
+			return err
+		}
+		return setMetaMap(values, m)
 	}
-	for i, row := range rows {
-		m := metaRow.FindStringSubmatch(row)
-		if len(m) != 3 {
+	return setRows(meta, m)
+}
+
+// setRows parses the [Key]: # (value) rows in any order, keeping the unknown ones
+func setRows(meta string, m meta) error {
+	var (
+		index = make(map[string]int)
+		seen  = make(map[string]bool)
+		extra = extraOf(m)
+	)
+	order, pointers := m.order(), m.pointers()
+	for i, k := range order {
+		index[k] = i
+	}
+	for _, row := range strings.Split(meta, "\n") {
+		r := metaRow.FindStringSubmatch(row)
+		if len(r) != 3 {
 			return fmt.Errorf("Invalid %q", row)
 		}
-		for {
-			if m[1] == order[i] {
-				if len(optionals) > 0 && order[i] == optionals[0] {
-					optionals = optionals[1:]
-				}
-				if err := setMetaValue(pointers[i], m[2]); err != nil {
-					return fmt.Errorf("meta: %s", err.Error())
-				}
-				break
-			}
-			if len(optionals) == 0 || order[i] != optionals[0] {
-				return fmt.Errorf("Expected %v, got %v", order[i], optionals[0])
+		if seen[r[1]] {
+			return fmt.Errorf("Duplicate %v", r[1])
+		}
+		seen[r[1]] = true
+		i, ok := index[r[1]]
+		if !ok {
+			if extra != nil {
+				extra.set(r[1], r[2])
 			}
-			optionals = optionals[1:]
-			pointers = pointers[:i+copy(pointers[i:], pointers[i+1:])]
-			order = order[:i+copy(order[i:], order[i+1:])]
+			continue
+		}
+		if err := setMetaValue(pointers[i], r[2]); err != nil {
+			return fmt.Errorf("meta: %s", err.Error())
+		}
+	}
+	return checkRequired(m, seen)
+}
+
+// checkRequired returns an error if a field that is not optional is missing
+func checkRequired(m meta, seen map[string]bool) error {
+	optional := make(map[string]bool)
+	for _, v := range m.optionals() {
+		optional[v] = true
+	}
+	for _, k := range m.order() {
+		if !seen[k] && !optional[k] {
+			return fmt.Errorf("Missing %v", k)
 		}
 	}
 	return nil
 }
 
+// metaValue returns the value of the row with the key
+func metaValue(meta, key string) string {
+	for _, row := range strings.Split(meta, "\n") {
+		if m := metaRow.FindStringSubmatch(row); len(m) == 3 && m[1] == key {
+			return m[2]
+		}
+	}
+	return ""
+}
+
 func setMetaValue(p interface{}, v string) error {
 	switch pointer := p.(type) {
 	case *string:
@@ -77,14 +122,29 @@ func setMetaValue(p interface{}, v string) error {
 			return nil
 		}
 		*pointer = strings.Split(v, ";")
+	case **time.Time:
+		if strings.TrimSpace(v) == "" {
+			return nil
+		}
+		t, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			if t, err = time.ParseInLocation(dateFormat, v, dateZone); err != nil {
+				return fmt.Errorf("Invalid time: %v", v)
+			}
+		}
+		*pointer = &t
 	default:
 		return fmt.Errorf("unknown type %T", pointer)
 	}
 	return nil
 }
 
+// getMeta returns the metadata as [Key]: # (value) rows, followed by the unknown ones
 func getMeta(m meta) string {
-	order, optionals := m.order(), m.optionals()
+	order, optional := m.order(), make(map[string]bool)
+	for _, v := range m.optionals() {
+		optional[v] = true
+	}
 	b := bytes.NewBuffer(nil)
 	for i, v := range m.values() {
 		var isZero bool
@@ -94,21 +154,39 @@ func getMeta(m meta) string {
 		case int, float64:
 			isZero = t == 0
 		case bool:
-			isZero = t
+			isZero = !t
 		case []string:
 			isZero = len(t) == 0 || len(t) == 1 && t[0] == ""
 			v = strings.Join(t, ";")
+		case *time.Time:
+			isZero = t == nil
+			v = formatTime(t)
 		}
-		if len(optionals) != 0 && order[i] == optionals[0] {
-			optionals = optionals[1:]
-			if isZero {
-				continue
-			}
+		if isZero && optional[order[i]] {
+			continue
 		}
-		if i > 0 {
+		if b.Len() > 0 {
 			b.WriteRune('\n')
 		}
 		fmt.Fprintf(b, "[%s]: # (%v)", order[i], v)
 	}
+	if extra := extraOf(m); extra != nil {
+		for _, row := range extra.rows() {
+			if b.Len() > 0 {
+				b.WriteRune('\n')
+			}
+			b.WriteString(row)
+		}
+	}
 	return b.String()
 }
+
+func formatTime(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	if t.Location() == dateZone {
+		return t.Format(dateFormat)
+	}
+	return t.Format(time.RFC3339)
+}
@@ -0,0 +1,502 @@
+package component
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gopkg.in/src-d/go-git.v4/plumbing/object"
+)
+
+var ErrProfile = errors.New("Invalid profile")
+
+// nodeID is the syntax of the ID of a new node
+var nodeID = regexp.MustCompile(`^[a-zA-Z0-9_-][a-zA-Z0-9_.-]*$`)
+
+// Level is a level of a content hierarchy, with its metadata fields and the
+// ones exported for translation (all but Order if empty)
+type Level struct {
+	Name      string   `json:"name"`
+	Fields    []string `json:"fields"`
+	Optionals []string `json:"optionals,omitempty"`
+	Translate []string `json:"translate,omitempty"`
+}
+
+// Profile is a content hierarchy: the nodes of every level but the last are
+// directories with a metadata file, the ones of the last are files with a body
+type Profile struct {
+	Name   string  `json:"name"`
+	Root   string  `json:"root"`
+	Levels []Level `json:"levels"`
+}
+
+func levelOf(name string, m meta) Level {
+	return Level{Name: name, Fields: m.order(), Optionals: m.optionals()}
+}
+
+// DefaultProfile is the category, subcategory, difficulty and item hierarchy
+var DefaultProfile = Profile{
+	Name: "default",
+	Root: "contents",
+	Levels: []Level{
+		levelOf("category", new(Category)),
+		levelOf("subcategory", new(Subcategory)),
+		levelOf("difficulty", new(Difficulty)),
+		levelOf("item", new(Item)),
+	},
+}
+
+// Validate returns an error if the profile has no root, levels or fields
+func (p *Profile) Validate() error {
+	if p.Root == "" || len(p.Levels) == 0 || strings.Contains(p.Root, "/") {
+		return ErrProfile
+	}
+	var names = make(map[string]bool)
+	for _, l := range p.Levels {
+		if l.Name == "" || names[l.Name] || len(l.Fields) == 0 {
+			return ErrProfile
+		}
+		names[l.Name] = true
+		var fields = make(map[string]bool)
+		for _, f := range l.Fields {
+			fields[f] = true
+		}
+		for _, f := range append(append([]string{}, l.Optionals...), l.Translate...) {
+			if !fields[f] {
+				return ErrProfile
+			}
+		}
+	}
+	return nil
+}
+
+// Depth returns the number of levels
+func (p *Profile) Depth() int { return len(p.Levels) }
+
+func (p *Profile) rootDir() *regexp.Regexp {
+	return regexp.MustCompile("^" + regexp.QuoteMeta(p.Root) + "(?:_([a-z]{2}))?$")
+}
+
+// Node is an element of a profile hierarchy
+type Node struct {
+	profile  *Profile
+	parent   *Node
+	level    int
+	fields   []string
+	children []*Node
+	ID       string
+	Locale   string
+	Hash     string
+	Body     string
+	Extra    Extra
+}
+
+// NewNode returns an empty node of the profile level
+func NewNode(p *Profile, level int) *Node {
+	return &Node{profile: p, level: level, fields: make([]string, len(p.Levels[level].Fields))}
+}
+
+// NewNode returns an empty node of the locale with the IDs, from the first level
+func (p *Profile) NewNode(locale string, ids ...string) (*Node, error) {
+	if len(ids) == 0 || len(ids) > p.Depth() {
+		return nil, ErrContent
+	}
+	var n *Node
+	for i, id := range ids {
+		if !nodeID.MatchString(id) {
+			return nil, ErrContent
+		}
+		v := NewNode(p, i)
+		v.ID, v.Locale, v.parent = id, locale, n
+		n = v
+	}
+	return n, nil
+}
+
+// Check returns an error if a required field is empty
+func (n *Node) Check() error {
+	var seen = make(map[string]bool)
+	for i, f := range n.Level().Fields {
+		seen[f] = n.fields[i] != ""
+	}
+	return checkRequired(nodeMeta{n}, seen)
+}
+
+// Level returns the level of the node
+func (n *Node) Level() Level { return n.profile.Levels[n.level] }
+
+func (n *Node) leaf() bool { return n.level == n.profile.Depth()-1 }
+
+// Get returns the value of the metadata field
+func (n *Node) Get(field string) string {
+	for i, f := range n.Level().Fields {
+		if f == field {
+			return n.fields[i]
+		}
+	}
+	return ""
+}
+
+// Has tells if the level of the node has the metadata field
+func (n *Node) Has(field string) bool {
+	for _, f := range n.Level().Fields {
+		if f == field {
+			return true
+		}
+	}
+	return false
+}
+
+// Set changes the value of the metadata field, it returns false if it's unknown
+func (n *Node) Set(field, value string) bool {
+	for i, f := range n.Level().Fields {
+		if f == field {
+			n.fields[i] = value
+			return true
+		}
+	}
+	return false
+}
+
+// Meta returns the metadata fields by name
+func (n *Node) Meta() map[string]string {
+	var m = make(map[string]string, len(n.fields))
+	for i, f := range n.Level().Fields {
+		m[f] = n.fields[i]
+	}
+	return m
+}
+
+func (n *Node) order() float64 {
+	v, _ := strconv.ParseFloat(n.Get("Order"), 64)
+	return v
+}
+
+// Children returns the nodes of the next level
+func (n *Node) Children() []*Node { return n.children }
+
+// Parent returns the node of the previous level, nil for the first one
+func (n *Node) Parent() *Node { return n.parent }
+
+// Published returns a copy of the node with only the published children
+func (n *Node) Published() *Node {
+	v := *n
+	v.children = make([]*Node, 0, len(n.children))
+	for _, c := range n.children {
+		if Published(c) {
+			v.children = append(v.children, c)
+		}
+	}
+	return &v
+}
+
+// Child returns the node of the next level with the ID
+func (n *Node) Child(id string) *Node {
+	for _, c := range n.children {
+		if c.ID == id {
+			return c
+		}
+	}
+	return nil
+}
+
+// IDs returns the IDs of the node and its parents, from the root
+func (n *Node) IDs() []string {
+	if n.parent == nil {
+		return []string{n.ID}
+	}
+	return append(n.parent.IDs(), n.ID)
+}
+
+func (n *Node) MarshalJSON() ([]byte, error) {
+	var children = make([]string, len(n.children))
+	for i, c := range n.children {
+		children[i] = c.ID
+	}
+	var m = map[string]interface{}{
+		"id":   n.ID,
+		"type": n.Level().Name,
+		"meta": n.Meta(),
+	}
+	if n.Hash != "" {
+		m["hash"] = n.Hash
+	}
+	if n.leaf() {
+		m["body"] = n.Body
+	} else {
+		m["children"] = children
+	}
+	if len(n.Extra) != 0 {
+		m["extra"] = n.Extra
+	}
+	return json.Marshal(m)
+}
+
+func (n *Node) State() string          { return n.Get("Status") }
+func (n *Node) SetState(status string) { n.Set("Status", status) }
+
+func (n *Node) HasChildren() bool { return len(n.children) != 0 }
+
+func (n *Node) SHA() string { return n.Hash }
+
+// Copy returns a copy of the node without children, that can be changed
+// without changing the original
+func (n *Node) Copy() *Node {
+	v := *n
+	v.fields, v.children = append([]string(nil), n.fields...), nil
+	return &v
+}
+
+// translated returns the fields to translate, all but Order if the level doesn't specify them
+func (n *Node) translated() []string {
+	translate := n.Level().Translate
+	if len(translate) == 0 {
+		for _, f := range n.Level().Fields {
+			if f != "Order" {
+				translate = append(translate, f)
+			}
+		}
+	}
+	return translate
+}
+
+func (n *Node) Resource() Resource {
+	content := make(map[string]string)
+	for _, f := range n.translated() {
+		content[strings.ToLower(f)] = n.Get(f)
+	}
+	if n.leaf() {
+		content["body"] = n.Body
+	}
+	slug := strings.Join(append([]string{n.profile.Name}, n.IDs()...), "_")
+	return Resource{Slug: slug, Content: []map[string]string{content}}
+}
+
+func (n *Node) dir() string {
+	root := n.profile.Root
+	if n.Locale != "" {
+		root += "_" + n.Locale
+	}
+	return strings.Join(append([]string{root}, n.IDs()...), "/")
+}
+
+func (n *Node) Path() string {
+	if n.leaf() {
+		return n.dir() + fileExt
+	}
+	return fmt.Sprintf("%s/%s%s", n.dir(), suffixMeta, fileExt)
+}
+
+// SetPath sets ID, locale and level of the node
+func (n *Node) SetPath(filepath string) error {
+	parts := strings.Split(filepath, "/")
+	m := n.profile.rootDir().FindStringSubmatch(parts[0])
+	if len(m) == 0 || len(parts) < 2 {
+		return ErrContent
+	}
+	name := parts[len(parts)-1]
+	switch {
+	case name == suffixMeta+fileExt:
+		n.level = len(parts) - 3
+		if n.level < 0 || n.level >= n.profile.Depth()-1 {
+			return ErrContent
+		}
+		n.ID = parts[len(parts)-2]
+	case isMd(name) && !strings.HasPrefix(name, "."):
+		n.level = len(parts) - 2
+		if !n.leaf() {
+			return ErrContent
+		}
+		n.ID = strings.TrimSuffix(name, fileExt)
+	default:
+		return ErrContent
+	}
+	n.Locale = m[1]
+	n.fields = make([]string, len(n.Level().Fields))
+	return nil
+}
+
+func (n *Node) Contents() string {
+	if n.leaf() {
+		return fmt.Sprint(getMeta(nodeMeta{n}), bodySeparator, n.Body)
+	}
+	return getMeta(nodeMeta{n})
+}
+
+func (n *Node) frontMatter() (string, error) {
+	meta, err := writeFrontMatter(metaMap(nodeMeta{n}))
+	if err != nil || !n.leaf() {
+		return meta, err
+	}
+	return fmt.Sprint(meta, bodySeparator, n.Body), nil
+}
+
+func (n *Node) SetContents(contents string) error {
+	m := nodeMeta{n}
+	if !n.leaf() {
+		return setMeta(contents, m)
+	}
+	contents = strings.Trim(contents, "\n")
+	if isFrontMatter(contents) {
+		values, rest, err := readFrontMatter(contents)
+		if err != nil {
+			return err
+		}
+		if err := setMetaMap(values, m); err != nil {
+			return err
+		}
+		n.Body = rest
+		return nil
+	}
+	parts := strings.SplitN(contents, bodySeparator, 2)
+	if len(parts) != 2 {
+		return ErrContent
+	}
+	if err := setRows(parts[0], m); err != nil {
+		return err
+	}
+	n.Body = parts[1]
+	return nil
+}
+
+// nodeMeta is the metadata of a node, with the fields of its level
+type nodeMeta struct{ *Node }
+
+func (m nodeMeta) order() []string     { return m.Level().Fields }
+func (m nodeMeta) optionals() []string { return m.Level().Optionals }
+func (m nodeMeta) extra() *Extra       { return &m.Extra }
+
+func (m nodeMeta) pointers() args {
+	var p = make(args, len(m.fields))
+	for i := range m.fields {
+		p[i] = &m.fields[i]
+	}
+	return p
+}
+
+func (m nodeMeta) values() args {
+	var v = make(args, len(m.fields))
+	for i := range m.fields {
+		v[i] = m.fields[i]
+	}
+	return v
+}
+
+// OrphanError is a node without its parent, skipped by the parse
+type OrphanError struct {
+	Path   string `json:"path"`
+	Locale string `json:"locale"`
+}
+
+func (e OrphanError) Error() string {
+	return fmt.Sprintf("%s: missing parent", e.Path)
+}
+
+// Parse creates the nodes of the first level of the profile, by locale,
+// returning the ones whose parent is missing apart
+func (p *Profile) Parse(t *object.Tree) (map[string][]*Node, []OrphanError, error) {
+	var (
+		root    = p.rootDir()
+		nodes   = make(map[string]*Node)
+		files   = make(map[string]string)
+		list    []*Node
+		orphans []OrphanError
+	)
+	for iter := t.Files(); ; {
+		f, err := iter.Next()
+		if err != nil {
+			if err == io.EOF {
+				break
+			}
+			return nil, nil, err
+		}
+		if !root.MatchString(strings.Split(f.Name, "/")[0]) {
+			continue
+		}
+		n := &Node{profile: p}
+		if err := n.SetPath(f.Name); err != nil {
+			continue
+		}
+		contents, err := f.Contents()
+		if err != nil {
+			return nil, nil, parseError{f.Name, "read", err}
+		}
+		if err := n.SetContents(strings.Replace(strings.TrimSpace(contents), "\r\n", "\n", -1)); err != nil {
+			return nil, nil, parseError{f.Name, "contents", err}
+		}
+		dir := strings.TrimSuffix(strings.TrimSuffix(f.Name, suffixMeta+fileExt), "/")
+		if n.leaf() {
+			dir = strings.TrimSuffix(f.Name, fileExt)
+		}
+		nodes[dir], files[dir] = n, f.Name
+		list = append(list, n)
+	}
+	var result = make(map[string][]*Node)
+	for dir, n := range nodes {
+		if n.level == 0 {
+			result[n.Locale] = append(result[n.Locale], n)
+			continue
+		}
+		parent, ok := nodes[dir[:strings.LastIndex(dir, "/")]]
+		if !ok {
+			orphans = append(orphans, OrphanError{Path: files[dir], Locale: n.Locale})
+			continue
+		}
+		n.parent = parent
+		parent.children = append(parent.children, n)
+	}
+	for _, n := range list {
+		sortNodes(n.children)
+	}
+	for l := range result {
+		sortNodes(result[l])
+	}
+	sort.Slice(orphans, func(i, j int) bool { return orphans[i].Path < orphans[j].Path })
+	return result, orphans, nil
+}
+
+// Flatten returns the nodes with all their children, depth first
+func Flatten(nodes []*Node) []*Node {
+	var list []*Node
+	for _, n := range nodes {
+		list = append(list, n)
+		list = append(list, Flatten(n.children)...)
+	}
+	return list
+}
+
+// Find returns the node with the IDs, from the first level
+func Find(nodes []*Node, ids ...string) *Node {
+	var n *Node
+	for i, id := range ids {
+		var next *Node
+		for _, v := range nodes {
+			if v.ID == id {
+				next = v
+				break
+			}
+		}
+		if next == nil {
+			return nil
+		}
+		if n = next; i < len(ids)-1 {
+			nodes = n.children
+		}
+	}
+	return n
+}
+
+// sortNodes sorts the nodes by Order, then by ID
+func sortNodes(n []*Node) {
+	sort.SliceStable(n, func(i, j int) bool {
+		if a, b := n[i].order(), n[j].order(); a != b {
+			return a < b
+		}
+		return n[i].ID < n[j].ID
+	})
+}
@@ -0,0 +1,80 @@
+package component
+
+import "testing"
+
+func TestNode(t *testing.T) {
+	guide := Profile{Name: "guides", Root: "guides", Levels: []Level{
+		{Name: "section", Fields: []string{"Name", "Order"}},
+		{Name: "page", Fields: []string{"Title", "Order", "Status"}, Optionals: []string{"Status"}},
+	}}
+	if err := guide.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	if err := (&Profile{Root: "x"}).Validate(); err != ErrProfile {
+		t.Errorf("expected %v, got %v", ErrProfile, err)
+	}
+	var testCases = []struct {
+		profile  *Profile
+		path     string
+		level    string
+		contents string
+		err      error
+	}{
+		{&guide, "guides_en/intro/.metadata.md", "section", "[Name]: # (Intro)\n[Order]: # (1)", nil},
+		{&guide, "guides_en/intro/start.md", "page", "[Title]: # (Start)\n[Order]: # (1)\n[Status]: # (draft)\n\nBody", nil},
+		{&guide, "guides_en/intro/start/.metadata.md", "", "", ErrContent},
+		{&guide, "guides_en/intro/start/deep/.metadata.md", "", "", ErrContent},
+		{&guide, "guides_en/intro/start/deep.md", "", "", ErrContent},
+		{&guide, "guides_en/start.md", "", "", ErrContent},
+		{&guide, "contents_en/intro/.metadata.md", "", "", ErrContent},
+		{&DefaultProfile, "contents_en/cat/sub/diff/.metadata.md", "difficulty", "[Description]: # (Beginner)", nil},
+		{&DefaultProfile, "contents_en/cat/sub/diff/.checks.md", "", "", ErrContent},
+		{&DefaultProfile, "contents_en/cat/sub/diff/item.md", "item", "[Title]: # (Item)\n[Order]: # (2)\n[Extra]: # (kept)\n\nBody", nil},
+	}
+	for _, tc := range testCases {
+		n := &Node{profile: tc.profile}
+		if err := n.SetPath(tc.path); err != tc.err {
+			t.Errorf("%s: expected %v, got %v", tc.path, tc.err, err)
+			continue
+		}
+		if tc.err != nil {
+			continue
+		}
+		if l := n.Level().Name; l != tc.level {
+			t.Errorf("%s: expected level %s, got %s", tc.path, tc.level, l)
+		}
+		if err := n.SetContents(tc.contents); err != nil {
+			t.Errorf("%s: %s", tc.path, err)
+			continue
+		}
+		if c := n.Contents(); c != tc.contents {
+			t.Errorf("%s: expected \n%q, got \n%q", tc.path, tc.contents, c)
+		}
+	}
+	n := &Node{profile: &guide}
+	n.SetPath("guides_it/intro/start.md")
+	n.SetContents("[Title]: # (Start)\n[Order]: # (1)\n[Status]: # (draft)\n\nBody")
+	if n.Locale != "it" || n.Get("Title") != "Start" || n.Body != "Body" || Published(n) {
+		t.Errorf("unexpected node %+v", n)
+	}
+}
+
+func TestNodePublished(t *testing.T) {
+	guide := Profile{Name: "guides", Root: "guides", Levels: []Level{
+		{Name: "section", Fields: []string{"Name", "Order"}},
+		{Name: "page", Fields: []string{"Title", "Order", "Status"}, Optionals: []string{"Status"}},
+	}}
+	section, _ := guide.NewNode("en", "intro")
+	for _, s := range []string{"start", "next"} {
+		n, _ := guide.NewNode("en", "intro", s)
+		n.parent, section.children = section, append(section.children, n)
+	}
+	section.children[1].SetState(StatusDraft)
+	v := section.Published()
+	if len(v.Children()) != 1 || v.Children()[0].ID != "start" || len(section.Children()) != 2 {
+		t.Errorf("unexpected children %v of %v", v.Children(), section.Children())
+	}
+	if p := v.Children()[0].Parent(); p != section {
+		t.Errorf("expected parent %v, got %v", section, p)
+	}
+}
@@ -10,10 +10,34 @@ import (
 
 // Parser is an helper, creates a tree from the repo
 type Parser struct {
+	Format     string // syntax used to write the metadata, rows if empty
 	index      map[[2]string]int
 	categories []*Category
 	assets     []*Asset
 	forms      []*Form
+	tags       TagIndex
+	refs       RefIndex
+	dangling   []RefError
+	exclude    []*Profile
+}
+
+// Exclude makes the parser skip the directories of the profiles, the ones of
+// the custom types are always skipped
+func (p *Parser) Exclude(profiles ...*Profile) {
+	p.exclude = append(p.exclude, profiles...)
+}
+
+func (p *Parser) excluded(name string) bool {
+	if TypeOf(name) != nil {
+		return true
+	}
+	root := strings.Split(name, "/")[0]
+	for _, v := range p.exclude {
+		if v.rootDir().MatchString(root) {
+			return true
+		}
+	}
+	return false
 }
 
 // Parse executes the parsing on a repo
@@ -30,11 +54,14 @@ func (p *Parser) Parse(t *object.Tree) error {
 	for i := range p.categories {
 		sort.Sort(subSorter(p.categories[i].subcategories))
 		for j := range p.categories[i].subcategories {
+			sort.Stable(diffSorter(p.categories[i].subcategories[j].difficulties))
 			for k := range p.categories[i].subcategories[j].difficulties {
 				sort.Sort(itemSorter(p.categories[i].subcategories[j].difficulties[k].items))
 			}
 		}
 	}
+	p.tags = tagIndex(p.categories)
+	p.refs, p.dangling = refIndex(p.categories)
 	return nil
 }
 
@@ -47,7 +74,7 @@ func (p *Parser) parse(t *object.Tree, fn func(name string) bool) error {
 			}
 			return err
 		}
-		if !fn(f.Name) {
+		if !fn(f.Name) || p.excluded(f.Name) {
 			continue
 		}
 		if err := p.parseFile(f); err != nil {
@@ -149,3 +176,18 @@ func (p *Parser) Assets() []*Asset {
 func (p *Parser) Forms() []*Form {
 	return p.forms
 }
+
+// Refs returns the index of the items referencing each item
+func (p *Parser) Refs() RefIndex {
+	return p.refs
+}
+
+// Dangling returns the references to items that don't exist
+func (p *Parser) Dangling() []RefError {
+	return p.dangling
+}
+
+// Tags returns the index of the tagged items
+func (p *Parser) Tags() TagIndex {
+	return p.tags
+}
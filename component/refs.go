@@ -0,0 +1,190 @@
+package component
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// RefScheme is the prefix of the references to items, as tent://cat/sub/diff/item
+const RefScheme = "tent://"
+
+// refURL is the API path of a referenced item
+const refURL = "%s/api/repo/category/%s/%s/%s/item/%s"
+
+var refPattern = regexp.MustCompile(`tent://([^/\s()\[\]<>"']+)/([^/\s()\[\]<>"']+)/([^/\s()\[\]<>"']+)/([^/\s()\[\]<>"'#?]*[\w-])`)
+
+// Ref is a reference to an item of the same locale
+type Ref struct {
+	Category    string `json:"category"`
+	Subcategory string `json:"subcategory"`
+	Difficulty  string `json:"difficulty"`
+	Item        string `json:"item"`
+}
+
+func (r Ref) String() string {
+	return fmt.Sprintf("%s%s/%s/%s/%s", RefScheme, r.Category, r.Subcategory, r.Difficulty, r.Item)
+}
+
+// URL returns the API path of the item under the prefix of the server, with the
+// locale if not empty
+func (r Ref) URL(prefix, locale string) string {
+	u := fmt.Sprintf(refURL, prefix, url.PathEscape(r.Category), url.PathEscape(r.Subcategory),
+		url.PathEscape(r.Difficulty), url.PathEscape(r.Item))
+	if locale != "" {
+		u += "?locale=" + url.QueryEscape(locale)
+	}
+	return u
+}
+
+// Path returns the path of the item in the locale
+func (r Ref) Path(locale string) string {
+	cat := Category{ID: r.Category, Locale: locale}
+	return fmt.Sprintf("%s/%s/%s/%s%s", cat.basePath(), r.Subcategory, r.Difficulty, r.Item, fileExt)
+}
+
+// RefError is a reference to an item that doesn't exist
+type RefError struct {
+	Path string `json:"path"`
+	Ref  string `json:"ref"`
+}
+
+func (e RefError) Error() string {
+	return fmt.Sprintf("%s: dangling reference %s", e.Path, e.Ref)
+}
+
+// parseRefs returns the references in the text, without duplicates
+func parseRefs(text string) []Ref {
+	var (
+		refs []Ref
+		seen = make(map[Ref]bool)
+	)
+	for _, m := range refPattern.FindAllStringSubmatch(text, -1) {
+		r := Ref{Category: m[1], Subcategory: m[2], Difficulty: m[3], Item: m[4]}
+		if seen[r] {
+			continue
+		}
+		seen[r] = true
+		refs = append(refs, r)
+	}
+	return refs
+}
+
+// resolveRefs replaces the references in the text with the API paths
+func resolveRefs(text, prefix, locale string) string {
+	return refPattern.ReplaceAllStringFunc(text, func(s string) string {
+		m := refPattern.FindStringSubmatch(s)
+		return Ref{Category: m[1], Subcategory: m[2], Difficulty: m[3], Item: m[4]}.URL(prefix, locale)
+	})
+}
+
+// LinkRefs renders the HTML body of the items of the locale again, with the
+// references linking the API path of the items under the prefix of the server
+func LinkRefs(categories []*Category, prefix, locale string) {
+	for _, cat := range categories {
+		for _, sub := range cat.subcategories {
+			for _, dif := range sub.difficulties {
+				for _, item := range dif.items {
+					item.htmlBody = item.html(prefix, locale)
+				}
+			}
+		}
+	}
+}
+
+// Prefix returns the path of the reference up to its last field, as cat/sub
+func (r Ref) Prefix() string {
+	var parts []string
+	for _, v := range r.fields() {
+		if v == "" {
+			break
+		}
+		parts = append(parts, v)
+	}
+	return strings.Join(parts, "/")
+}
+
+func (r Ref) fields() []string {
+	return []string{r.Category, r.Subcategory, r.Difficulty, r.Item}
+}
+
+// Level returns the number of fields of a partial reference, 4 for an item
+func (r Ref) Level() int {
+	p := r.Prefix()
+	if p == "" {
+		return 0
+	}
+	return len(strings.Split(p, "/"))
+}
+
+// Under returns true if the reference is the partial one or one of its children
+func (r Ref) Under(prefix Ref) bool {
+	f := r.fields()
+	for i, v := range prefix.fields() {
+		if v == "" {
+			break
+		}
+		if f[i] != v {
+			return false
+		}
+	}
+	return true
+}
+
+// MoveRefs rewrites the references under from, a partial reference, as under to
+func MoveRefs(text string, from, to Ref) string {
+	return refPattern.ReplaceAllStringFunc(text, func(s string) string {
+		m := refPattern.FindStringSubmatch(s)
+		ref := Ref{Category: m[1], Subcategory: m[2], Difficulty: m[3], Item: m[4]}
+		if !ref.Under(from) {
+			return s
+		}
+		f, t := ref.fields(), to.fields()
+		for i, v := range from.fields() {
+			if v == "" {
+				break
+			}
+			f[i] = t[i]
+		}
+		return Ref{Category: f[0], Subcategory: f[1], Difficulty: f[2], Item: f[3]}.String()
+	})
+}
+
+// RefIndex contains the items referencing each item, by path
+type RefIndex map[string][]*Item
+
+// refIndex indexes the references between the items, returning the dangling ones
+func refIndex(categories []*Category) (RefIndex, []RefError) {
+	var (
+		items    = make(map[string]bool)
+		index    = make(RefIndex)
+		dangling []RefError
+	)
+	for _, cat := range categories {
+		for _, sub := range cat.subcategories {
+			for _, dif := range sub.difficulties {
+				for _, item := range dif.items {
+					items[item.Path()] = true
+				}
+			}
+		}
+	}
+	for _, cat := range categories {
+		for _, sub := range cat.subcategories {
+			for _, dif := range sub.difficulties {
+				for _, item := range dif.items {
+					for _, r := range item.Refs() {
+						p := r.Path(cat.Locale)
+						if !items[p] {
+							dangling = append(dangling, RefError{Path: item.Path(), Ref: r.String()})
+							continue
+						}
+						index[p] = append(index[p], item)
+					}
+				}
+			}
+		}
+	}
+	return index, dangling
+}
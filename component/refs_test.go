@@ -0,0 +1,77 @@
+package component
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestRefs(t *testing.T) {
+	newItem := func(id, body string) *Item {
+		i := &Item{ID: id}
+		if err := i.SetContents("[Title]: # (" + id + ")\n[Order]: # (1)\n\n" + body); err != nil {
+			t.Fatal(err)
+		}
+		return i
+	}
+	a := newItem("a", "See [b](tent://cat/sub/beginner/b) and tent://cat/sub/beginner/b.")
+	b := newItem("b", "Back to [a](tent://cat/sub/beginner/a), or [c](tent://cat/sub/expert/c).")
+	cat := &Category{ID: "cat", Locale: "en"}
+	sub := &Subcategory{ID: "sub"}
+	dif := &Difficulty{ID: "beginner"}
+	dif.AddItem(a, b)
+	sub.AddDifficulty(dif)
+	cat.Add(sub)
+
+	if refs := a.Refs(); !reflect.DeepEqual(refs, []Ref{b.Ref()}) {
+		t.Errorf("unexpected refs %v", refs)
+	}
+	if s := b.Ref().String(); s != "tent://cat/sub/beginner/b" {
+		t.Errorf("unexpected ref %s", s)
+	}
+	if !strings.Contains(a.htmlBody, `href="/api/repo/category/cat/sub/beginner/item/b"`) {
+		t.Errorf("unresolved ref in %q", a.htmlBody)
+	}
+	LinkRefs([]*Category{cat}, "/tent", "en")
+	if !strings.Contains(a.htmlBody, `href="/tent/api/repo/category/cat/sub/beginner/item/b?locale=en"`) {
+		t.Errorf("unresolved ref in %q", a.htmlBody)
+	}
+	if u := (Ref{"a b", "sub", "diff", "c?d"}).URL("", "pt-BR"); u != "/api/repo/category/a%20b/sub/diff/item/c%3Fd?locale=pt-BR" {
+		t.Errorf("unexpected URL %s", u)
+	}
+	index, dangling := refIndex([]*Category{cat})
+	if v := index[b.Path()]; !reflect.DeepEqual(v, []*Item{a}) {
+		t.Errorf("unexpected referenced by %v", v)
+	}
+	if v := index[a.Path()]; !reflect.DeepEqual(v, []*Item{b}) {
+		t.Errorf("unexpected referenced by %v", v)
+	}
+	expected := []RefError{{Path: b.Path(), Ref: "tent://cat/sub/expert/c"}}
+	if !reflect.DeepEqual(dangling, expected) {
+		t.Errorf("expected %v, got %v", expected, dangling)
+	}
+}
+
+func TestMoveRefs(t *testing.T) {
+	text := "[a](tent://cat/sub/diff/a), [b](tent://cat/sub2/diff/b) and tent://cat/sub/diff/c."
+	var testCases = []struct {
+		from, to Ref
+		expected string
+	}{
+		{Ref{Category: "cat"}, Ref{Category: "new"},
+			"[a](tent://new/sub/diff/a), [b](tent://new/sub2/diff/b) and tent://new/sub/diff/c."},
+		{Ref{Category: "cat", Subcategory: "sub"}, Ref{Category: "other", Subcategory: "moved"},
+			"[a](tent://other/moved/diff/a), [b](tent://cat/sub2/diff/b) and tent://other/moved/diff/c."},
+		{Ref{"cat", "sub", "diff", "a"}, Ref{"cat", "sub2", "diff", "a2"},
+			"[a](tent://cat/sub2/diff/a2), [b](tent://cat/sub2/diff/b) and tent://cat/sub/diff/c."},
+		{Ref{Category: "none"}, Ref{Category: "new"}, text},
+	}
+	for _, tc := range testCases {
+		if s := MoveRefs(text, tc.from, tc.to); s != tc.expected {
+			t.Errorf("%s to %s: expected \n%q, got \n%q", tc.from.Prefix(), tc.to.Prefix(), tc.expected, s)
+		}
+		if l := tc.from.Level(); l != tc.to.Level() {
+			t.Errorf("%s: unexpected level %d", tc.from.Prefix(), l)
+		}
+	}
+}
@@ -0,0 +1,69 @@
+package component
+
+import (
+	"errors"
+	"sort"
+	"strings"
+	"sync"
+)
+
+var ErrType = errors.New("Invalid type")
+
+// reserved are the directories used by the built-in components
+var reserved = []string{"contents", "forms", "assets"}
+
+var registry = struct {
+	sync.RWMutex
+	types map[string]*Profile
+}{types: make(map[string]*Profile)}
+
+// Register adds a custom component type, a hierarchy with its own name and root
+func Register(p Profile) error {
+	if err := p.Validate(); err != nil {
+		return err
+	}
+	registry.Lock()
+	defer registry.Unlock()
+	for _, v := range reserved {
+		if p.Root == v {
+			return ErrType
+		}
+	}
+	for _, t := range registry.types {
+		if t.Name == p.Name || t.Root == p.Root {
+			return ErrType
+		}
+	}
+	registry.types[p.Name] = &p
+	return nil
+}
+
+// Types returns the custom component types, sorted by name
+func Types() []*Profile {
+	registry.RLock()
+	defer registry.RUnlock()
+	var list = make([]*Profile, 0, len(registry.types))
+	for _, t := range registry.types {
+		list = append(list, t)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Name < list[j].Name })
+	return list
+}
+
+// TypeByName returns the custom component type with the name, nil if missing
+func TypeByName(name string) *Profile {
+	registry.RLock()
+	defer registry.RUnlock()
+	return registry.types[name]
+}
+
+// TypeOf returns the custom component type of the path, nil if none
+func TypeOf(path string) *Profile {
+	root := strings.Split(path, "/")[0]
+	for _, t := range Types() {
+		if t.rootDir().MatchString(root) {
+			return t
+		}
+	}
+	return nil
+}
@@ -0,0 +1,65 @@
+package component
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestRegistry(t *testing.T) {
+	tool := Profile{Name: "tool", Root: "tools", Levels: []Level{
+		{Name: "group", Fields: []string{"Name", "Order"}},
+		{Name: "tool", Fields: []string{"Name", "Rating", "Order"}, Translate: []string{"Name"}},
+	}}
+	var testCases = []struct {
+		profile Profile
+		err     error
+	}{
+		{tool, nil},
+		{Profile{Name: "tool", Root: "other", Levels: tool.Levels}, ErrType},
+		{Profile{Name: "other", Root: "tools", Levels: tool.Levels}, ErrType},
+		{Profile{Name: "other", Root: "contents", Levels: tool.Levels}, ErrType},
+		{Profile{Name: "other", Root: "other", Levels: []Level{{Name: "a", Fields: []string{"A"}, Translate: []string{"B"}}}}, ErrProfile},
+	}
+	for _, tc := range testCases {
+		if err := Register(tc.profile); err != tc.err {
+			t.Errorf("%s: expected %v, got %v", tc.profile.Name, tc.err, err)
+		}
+	}
+	defer delete(registry.types, "tool")
+
+	if p := TypeOf("tools_en/vpn/wireguard.md"); p == nil || p.Name != "tool" {
+		t.Errorf("unexpected type %v", p)
+	}
+	if p := TypeOf("contents_en/cat/.metadata.md"); p != nil {
+		t.Errorf("unexpected type %v", p)
+	}
+	n, err := TypeByName("tool").NewNode("en", "vpn", "wireguard")
+	if err != nil {
+		t.Fatal(err)
+	}
+	n.Set("Name", "Wireguard")
+	n.Body = "Fast."
+	if err := n.Check(); err == nil {
+		t.Error("expected missing fields")
+	}
+	n.Set("Rating", "5")
+	n.Set("Order", "1")
+	if err := n.Check(); err != nil {
+		t.Error(err)
+	}
+	if p := n.Path(); p != "tools_en/vpn/wireguard.md" {
+		t.Errorf("unexpected path %s", p)
+	}
+	expected := Resource{Slug: "tool_vpn_wireguard", Content: []map[string]string{{"name": "Wireguard", "body": "Fast."}}}
+	if r := n.Resource(); !reflect.DeepEqual(r, expected) {
+		t.Errorf("expected %v, got %v", expected, r)
+	}
+	if _, err := TypeByName("tool").NewNode("en", "vpn", "wireguard", "extra"); err != ErrContent {
+		t.Errorf("expected %v, got %v", ErrContent, err)
+	}
+	for _, id := range []string{"", ".hidden", "a/b", `x"onclick`, "r&d", "a b"} {
+		if _, err := TypeByName("tool").NewNode("en", id); err != ErrContent {
+			t.Errorf("%q: expected %v, got %v", id, ErrContent, err)
+		}
+	}
+}
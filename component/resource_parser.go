@@ -17,6 +17,7 @@ func NewResourceParser() *ResourceParser {
 	return &ResourceParser{
 		categories: make(map[string][]*Category),
 		forms:      make(map[string][]*Form),
+		nodes:      make(map[string][]*Node),
 	}
 }
 
@@ -24,10 +25,14 @@ type ResourceParser struct {
 	buffer     bytes.Buffer
 	categories map[string][]*Category
 	forms      map[string][]*Form
+	nodes      map[string][]*Node
 }
 
 func (r *ResourceParser) Categories() map[string][]*Category { return r.categories }
 
+// Nodes returns the translated nodes by locale
+func (r *ResourceParser) Nodes() map[string][]*Node { return r.nodes }
+
 func (r *ResourceParser) Parse(cmp Component, res *Resource, locale string) error {
 	switch v := cmp.(type) {
 	case *Form:
@@ -42,6 +47,8 @@ func (r *ResourceParser) Parse(cmp Component, res *Resource, locale string) erro
 		return r.parseItem(v, res, locale)
 	case *Checklist:
 		return r.parseChecklist(v, res, locale)
+	case *Node:
+		return r.parseNode(v, res, locale)
 	default:
 		return errors.New("Invalid Component")
 	}
@@ -49,10 +56,13 @@ func (r *ResourceParser) Parse(cmp Component, res *Resource, locale string) erro
 
 func (r *ResourceParser) parseForm(f *Form, res *Resource, locale string) error {
 	var newForm = Form{
-		ID:      f.ID,
-		Name:    res.Content[0]["form"],
-		Locale:  locale,
-		Screens: make([]FormScreen, len(f.Screens)),
+		ID:        f.ID,
+		Name:      res.Content[0]["form"],
+		Locale:    locale,
+		Status:    f.Status,
+		PublishAt: f.PublishAt,
+		ExpireAt:  f.ExpireAt,
+		Screens:   make([]FormScreen, len(f.Screens)),
 	}
 	m := res.Content[1:]
 	for i := range newForm.Screens {
@@ -142,7 +152,7 @@ func (r *ResourceParser) getDifficulty(diff *Difficulty, locale string) *Difficu
 			return d
 		}
 	}
-	d := Difficulty{ID: diff.ID}
+	d := Difficulty{ID: diff.ID, Order: diff.Order}
 	sub.AddDifficulty(&d)
 	return &d
 }
@@ -161,9 +171,12 @@ func (r *ResourceParser) parseItem(i *Item, res *Resource, locale string) error
 		return ErrContent
 	}
 	item := &Item{
-		ID:    i.ID,
-		Title: strings.TrimSpace(res.Content[0]["title"]),
-		Order: i.Order,
+		ID:        i.ID,
+		Title:     strings.TrimSpace(res.Content[0]["title"]),
+		Order:     i.Order,
+		Status:    i.Status,
+		PublishAt: i.PublishAt,
+		ExpireAt:  i.ExpireAt,
 	}
 	r.buffer.Reset()
 	// Old Verion Compatibility
@@ -193,7 +206,7 @@ func (r *ResourceParser) parseChecklist(c *Checklist, res *Resource, locale stri
 		return fmt.Errorf("%d checks, %d expected", l, e)
 	}
 
-	var checks Checklist
+	checks := Checklist{Status: c.Status}
 	for i, r := range res.Content {
 		checks.Add(Check{
 			Text:    strings.TrimSpace(r["text"]),
@@ -203,3 +216,21 @@ func (r *ResourceParser) parseChecklist(c *Checklist, res *Resource, locale stri
 	r.getDifficulty(c.parent, locale).SetChecks(&checks)
 	return nil
 }
+
+func (r *ResourceParser) parseNode(n *Node, res *Resource, locale string) error {
+	if len(res.Content) != 1 {
+		return ErrContent
+	}
+	v := n.Copy()
+	v.Locale, v.Hash = locale, ""
+	for _, f := range n.translated() {
+		if s, ok := res.Content[0][strings.ToLower(f)]; ok {
+			v.Set(f, strings.TrimSpace(s))
+		}
+	}
+	if body, ok := res.Content[0]["body"]; ok && n.leaf() {
+		v.Body = strings.TrimSpace(body)
+	}
+	r.nodes[locale] = append(r.nodes[locale], v)
+	return nil
+}
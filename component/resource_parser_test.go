@@ -59,3 +59,40 @@ func (CmpSuite) TestParseResource(c *C) {
 	err = p.Parse(&legacyCmp, &legacyRes, "it")
 	c.Assert(err, NotNil)
 }
+
+func (CmpSuite) TestParseResourceStatus(c *C) {
+	cat := Category{ID: "cat", Locale: "en", Name: "Category"}
+	sub := Subcategory{ID: "sub", Name: "Sub", parent: &cat}
+	dif := Difficulty{ID: "dif", parent: &sub}
+	item := Item{ID: "item", parent: &dif}
+	c.Assert(item.SetContents("[Title]: # (Item)\n[Order]: # (1)\n[Status]: # (draft)\n[PublishAt]: # (2019-03-01)\n[ExpireAt]: # (2019-04-01)\n\nBody"), IsNil)
+
+	res := item.Resource()
+	res.Content = []map[string]string{{"title": "Voce"}, {"body": "Testo"}}
+	p := NewResourceParser()
+	c.Assert(p.Parse(&item, &res, "it"), IsNil)
+	translated := p.Categories()["it"][0].Sub("sub").Difficulty("dif").Item("item")
+	c.Assert(translated.Status, Equals, StatusDraft)
+	c.Assert(translated.Contents(), Equals, "[Title]: # (Voce)\n[Order]: # (1)\n[Status]: # (draft)\n[PublishAt]: # (2019-03-01)\n[ExpireAt]: # (2019-04-01)\n\nTesto")
+	c.Assert(IsPublished(translated.Status), Equals, false)
+}
+
+func (CmpSuite) TestParseNodeResource(c *C) {
+	guide := Profile{Name: "guides", Root: "guides", Levels: []Level{
+		{Name: "section", Fields: []string{"Name", "Order"}},
+		{Name: "page", Fields: []string{"Title", "Order"}},
+	}}
+	n, err := guide.NewNode("en", "intro", "start")
+	c.Assert(err, IsNil)
+	c.Assert(n.SetContents("[Title]: # (Start)\n[Order]: # (1)\n\nBody"), IsNil)
+
+	res := n.Resource()
+	res.Content[0] = map[string]string{"title": "Inizio", "body": "Testo"}
+	p := NewResourceParser()
+	c.Assert(p.Parse(n, &res, "it"), IsNil)
+	nodes := p.Nodes()["it"]
+	c.Assert(nodes, HasLen, 1)
+	c.Assert(nodes[0].Path(), Equals, "guides_it/intro/start.md")
+	c.Assert(nodes[0].Contents(), Equals, "[Title]: # (Inizio)\n[Order]: # (1)\n\nTesto")
+	c.Assert(n.Get("Title"), Equals, "Start")
+}
@@ -0,0 +1,46 @@
+package component
+
+import (
+	"errors"
+	"time"
+)
+
+var ErrReview = errors.New("Invalid review period")
+
+const day = 24 * time.Hour
+
+// Reviewable is a component that needs a periodic expert review
+type Reviewable interface {
+	Component
+	Review() (last *time.Time, every int)
+}
+
+// ReviewDue returns when the next review is due, false if the component has no review period
+func ReviewDue(c Component) (time.Time, bool) {
+	r, ok := c.(Reviewable)
+	if !ok {
+		return time.Time{}, false
+	}
+	last, every := r.Review()
+	if every <= 0 {
+		return time.Time{}, false
+	}
+	if last == nil {
+		return time.Time{}, true
+	}
+	return last.Add(time.Duration(every) * day), true
+}
+
+// Stale tells if the review of the component is overdue
+func Stale(c Component, now time.Time) bool {
+	due, ok := ReviewDue(c)
+	return ok && now.After(due)
+}
+
+// checkReview returns an error if the period is negative
+func checkReview(every int) error {
+	if every < 0 {
+		return ErrReview
+	}
+	return nil
+}
@@ -0,0 +1,34 @@
+package component
+
+import (
+	"testing"
+	"time"
+)
+
+func TestReview(t *testing.T) {
+	now := time.Date(2019, 3, 1, 0, 0, 0, 0, time.UTC)
+	var testCases = []struct {
+		cmp      Component
+		contents string
+		stale    bool
+	}{
+		{new(Item), "[Title]: # (Title)\n[Order]: # (1)\n\nBody", false},
+		{new(Item), "[Title]: # (Title)\n[Order]: # (1)\n[ReviewEvery]: # (30)\n\nBody", true},
+		{new(Item), "[Title]: # (Title)\n[Order]: # (1)\n[LastReviewed]: # (2019-02-15)\n[ReviewEvery]: # (30)\n\nBody", false},
+		{new(Item), "[Title]: # (Title)\n[Order]: # (1)\n[LastReviewed]: # (2019-01-15)\n[ReviewEvery]: # (30)\n\nBody", true},
+		{new(Checklist), "[LastReviewed]: # (2018-01-01)\n[ReviewEvery]: # (90)\n\n[Text]: # (Check)\n[NoCheck]: # (false)", true},
+		{new(Checklist), "[Status]: # (draft)\n[LastReviewed]: # (2019-02-01)\n[ReviewEvery]: # (90)\n\n[Text]: # (Check)\n[NoCheck]: # (false)", false},
+	}
+	for _, tc := range testCases {
+		if err := tc.cmp.SetContents(tc.contents); err != nil {
+			t.Errorf("%q: %s", tc.contents, err)
+			continue
+		}
+		if s := Stale(tc.cmp, now); s != tc.stale {
+			t.Errorf("%q: expected stale %v, got %v", tc.contents, tc.stale, s)
+		}
+		if c := tc.cmp.Contents(); c != tc.contents {
+			t.Errorf("expected \n%q, got \n%q", tc.contents, c)
+		}
+	}
+}
@@ -1,5 +1,7 @@
 package component
 
+import "sort"
+
 type catSorter []*Category
 
 func (s catSorter) Len() int           { return len(s) }
@@ -12,8 +14,94 @@ func (s subSorter) Len() int           { return len(s) }
 func (s subSorter) Swap(i, j int)      { s[i], s[j] = s[j], s[i] }
 func (s subSorter) Less(i, j int) bool { return s[i].Order < s[j].Order }
 
+type diffSorter []*Difficulty
+
+func (s diffSorter) Len() int           { return len(s) }
+func (s diffSorter) Swap(i, j int)      { s[i], s[j] = s[j], s[i] }
+func (s diffSorter) Less(i, j int) bool { return s[i].Order < s[j].Order }
+
 type itemSorter []*Item
 
 func (s itemSorter) Len() int           { return len(s) }
 func (s itemSorter) Swap(i, j int)      { s[i], s[j] = s[j], s[i] }
 func (s itemSorter) Less(i, j int) bool { return s[i].Order < s[j].Order }
+
+// Reorder returns the orders of a list sorted in a new way, keeping as many
+// values as possible, or 1 to n if there's no room between them
+func Reorder(orders []float64) []float64 {
+	var (
+		n      = len(orders)
+		result = make([]float64, n)
+		keep   = increasing(orders)
+	)
+	for i, k := range keep {
+		if k {
+			result[i] = orders[i]
+		}
+	}
+	for i := 0; i < n; {
+		if keep[i] {
+			i++
+			continue
+		}
+		j := i
+		for j < n && !keep[j] {
+			j++
+		}
+		lo, hi := float64(0), float64(j-i+1)
+		switch {
+		case i > 0 && j < n:
+			lo, hi = result[i-1], result[j]
+		case i > 0:
+			lo, hi = result[i-1], result[i-1]+float64(j-i+1)
+		case j < n:
+			lo, hi = result[j]-float64(j-i+1), result[j]
+		}
+		step := (hi - lo) / float64(j-i+1)
+		for k := i; k < j; k++ {
+			result[k] = lo + step*float64(k-i+1)
+		}
+		i = j
+	}
+	if !sort.Float64sAreSorted(result) || hasDuplicates(result) {
+		for i := range result {
+			result[i] = float64(i + 1)
+		}
+	}
+	return result
+}
+
+// increasing marks the longest strictly increasing subsequence of the values
+func increasing(v []float64) []bool {
+	var (
+		n    = len(v)
+		size = make([]int, n)
+		prev = make([]int, n)
+		best = -1
+	)
+	for i := range v {
+		size[i], prev[i] = 1, -1
+		for j := 0; j < i; j++ {
+			if v[j] < v[i] && size[j]+1 > size[i] {
+				size[i], prev[i] = size[j]+1, j
+			}
+		}
+		if best < 0 || size[i] > size[best] {
+			best = i
+		}
+	}
+	var keep = make([]bool, n)
+	for i := best; i >= 0; i = prev[i] {
+		keep[i] = true
+	}
+	return keep
+}
+
+func hasDuplicates(v []float64) bool {
+	for i := 1; i < len(v); i++ {
+		if v[i] == v[i-1] {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,25 @@
+package component
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestReorder(t *testing.T) {
+	var testCases = []struct {
+		orders, expected []float64
+	}{
+		{[]float64{1, 2, 3}, []float64{1, 2, 3}},
+		{[]float64{2, 1}, []float64{2, 3}},
+		{[]float64{1, 3, 2, 4}, []float64{1, 3, 3.5, 4}},
+		{[]float64{4, 1, 2, 3}, []float64{0, 1, 2, 3}},
+		{[]float64{1, 1, 1}, []float64{1, 2, 3}},
+		{[]float64{0, 0}, []float64{0, 1}},
+		{nil, []float64{}},
+	}
+	for _, tc := range testCases {
+		if v := Reorder(tc.orders); !reflect.DeepEqual(v, tc.expected) {
+			t.Errorf("%v: expected %v, got %v", tc.orders, tc.expected, v)
+		}
+	}
+}
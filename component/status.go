@@ -0,0 +1,87 @@
+package component
+
+import (
+	"errors"
+	"time"
+)
+
+// Workflow states of a component, an empty status is published
+const (
+	StatusDraft     = "draft"
+	StatusReview    = "review"
+	StatusPublished = "published"
+)
+
+var (
+	ErrStatus   = errors.New("Invalid status")
+	ErrSchedule = errors.New("Invalid schedule")
+)
+
+// Stateful is a component with a workflow status
+type Stateful interface {
+	Component
+	State() string
+	SetState(status string)
+}
+
+// ValidStatus tells if the status is a known one
+func ValidStatus(s string) bool {
+	switch s {
+	case StatusDraft, StatusReview, StatusPublished:
+		return true
+	}
+	return false
+}
+
+// IsPublished tells if the status is visible to the public
+func IsPublished(s string) bool { return s == "" || s == StatusPublished }
+
+// Scheduled is a component with optional publication and expiry dates
+type Scheduled interface {
+	Component
+	Schedule() (publish, expire *time.Time)
+}
+
+// InSchedule tells if the time is between the publication and the expiry
+func InSchedule(c Component, now time.Time) bool {
+	s, ok := c.(Scheduled)
+	if !ok {
+		return true
+	}
+	publish, expire := s.Schedule()
+	return (publish == nil || !now.Before(*publish)) && (expire == nil || now.Before(*expire))
+}
+
+// Published tells if the component is visible to the public now, components
+// without a status or dates are always published
+func Published(c Component) bool {
+	if s, ok := c.(Stateful); ok && !IsPublished(s.State()) {
+		return false
+	}
+	return InSchedule(c, time.Now())
+}
+
+// CheckSchedule returns ErrSchedule if the component expires before its publication
+func CheckSchedule(c Component) error {
+	s, ok := c.(Scheduled)
+	if !ok {
+		return nil
+	}
+	return checkSchedule(s.Schedule())
+}
+
+// checkStatus returns an error if the status is not valid
+func checkStatus(s string) error {
+	if s != "" && !ValidStatus(s) {
+		return ErrStatus
+	}
+	return nil
+}
+
+// checkSchedule returns an error if the expiry is not after the publication
+func checkSchedule(publish, expire *time.Time) error {
+	if publish != nil && expire != nil && !expire.After(*publish) {
+		return ErrSchedule
+	}
+	return nil
+}
@@ -0,0 +1,79 @@
+package component
+
+import "testing"
+
+func TestStatus(t *testing.T) {
+	var testCases = []struct {
+		cmp      Stateful
+		contents string
+		status   string
+		err      bool
+	}{
+		{new(Item), "[Title]: # (Title)\n[Order]: # (1)\n\nBody", "", false},
+		{new(Item), "[Title]: # (Title)\n[Order]: # (1)\n[Status]: # (draft)\n\nBody", StatusDraft, false},
+		{new(Item), "[Title]: # (Title)\n[Order]: # (1)\n[Status]: # (unknown)\n\nBody", "", true},
+		{new(Form), "[Name]: # (Form)\n[Status]: # (review)", StatusReview, false},
+		{new(Checklist), "[Text]: # (Check)\n[NoCheck]: # (false)", "", false},
+		{new(Checklist), "[Status]: # (draft)\n\n[Text]: # (Check)\n[NoCheck]: # (false)", StatusDraft, false},
+	}
+	for _, tc := range testCases {
+		err := tc.cmp.SetContents(tc.contents)
+		if tc.err {
+			if err == nil {
+				t.Errorf("%q: expected error", tc.contents)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%q: %s", tc.contents, err)
+			continue
+		}
+		if s := tc.cmp.State(); s != tc.status {
+			t.Errorf("%q: expected status %q, got %q", tc.contents, tc.status, s)
+		}
+		if c := tc.cmp.Contents(); c != tc.contents {
+			t.Errorf("expected \n%q, got \n%q", tc.contents, c)
+		}
+		if Published(tc.cmp) != IsPublished(tc.status) {
+			t.Errorf("%q: wrong visibility", tc.contents)
+		}
+	}
+}
+
+func TestSchedule(t *testing.T) {
+	var testCases = []struct {
+		contents string
+		visible  bool
+		err      bool
+	}{
+		{"[Title]: # (Title)\n[Order]: # (1)\n[PublishAt]: # (2000-01-01)\n\nBody", true, false},
+		{"[Title]: # (Title)\n[Order]: # (1)\n[PublishAt]: # (2999-01-01T10:00:00Z)\n\nBody", false, false},
+		{"[Title]: # (Title)\n[Order]: # (1)\n[PublishAt]: # (2000-01-01T00:00:00Z)\n\nBody", true, false},
+		{"[Title]: # (Title)\n[Order]: # (1)\n[PublishAt]: # (2000-01-01T00:00:00+02:00)\n\nBody", true, false},
+		{"[Title]: # (Title)\n[Order]: # (1)\n[ExpireAt]: # (2000-01-01)\n\nBody", false, false},
+		{"[Title]: # (Title)\n[Order]: # (1)\n[Status]: # (draft)\n[PublishAt]: # (2000-01-01)\n\nBody", false, false},
+		{"[Title]: # (Title)\n[Order]: # (1)\n[PublishAt]: # (2000-01-01)\n[ExpireAt]: # (2999-01-01)\n\nBody", true, false},
+		{"[Title]: # (Title)\n[Order]: # (1)\n[PublishAt]: # (2999-01-01)\n[ExpireAt]: # (2000-01-01)\n\nBody", false, true},
+		{"[Title]: # (Title)\n[Order]: # (1)\n[PublishAt]: # (tomorrow)\n\nBody", false, true},
+	}
+	for _, tc := range testCases {
+		var i Item
+		err := i.SetContents(tc.contents)
+		if tc.err {
+			if err == nil {
+				t.Errorf("%q: expected error", tc.contents)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%q: %s", tc.contents, err)
+			continue
+		}
+		if v := Published(&i); v != tc.visible {
+			t.Errorf("%q: expected visible %v, got %v", tc.contents, tc.visible, v)
+		}
+		if c := i.Contents(); c != tc.contents {
+			t.Errorf("expected \n%q, got \n%q", tc.contents, c)
+		}
+	}
+}
@@ -0,0 +1,73 @@
+package component
+
+import (
+	"sort"
+	"strings"
+)
+
+// Tagged is an item found by tag, with its position in the tree
+type Tagged struct {
+	Category    string `json:"category"`
+	Subcategory string `json:"subcategory"`
+	Difficulty  string `json:"difficulty"`
+	Item        *Item  `json:"item"`
+}
+
+// TagIndex contains the tagged items by locale and tag
+type TagIndex map[string]map[string][]Tagged
+
+// Tags returns the tags of the locale, sorted
+func (t TagIndex) Tags(locale string) []string {
+	var tags = make([]string, 0, len(t[locale]))
+	for k := range t[locale] {
+		tags = append(tags, k)
+	}
+	sort.Strings(tags)
+	return tags
+}
+
+// Items returns the items of the locale with the tag
+func (t TagIndex) Items(locale, tag string) []Tagged {
+	return t[locale][NormalizeTag(tag)]
+}
+
+// NormalizeTag returns the tag as it's used in the index
+func NormalizeTag(tag string) string {
+	return strings.ToLower(strings.TrimSpace(tag))
+}
+
+func (t TagIndex) add(locale string, tags []string, v Tagged) {
+	if t[locale] == nil {
+		t[locale] = make(map[string][]Tagged)
+	}
+	var seen = make(map[string]bool, len(tags))
+	for _, tag := range tags {
+		tag = NormalizeTag(tag)
+		if tag == "" || seen[tag] {
+			continue
+		}
+		seen[tag] = true
+		t[locale][tag] = append(t[locale][tag], v)
+	}
+}
+
+// tagIndex indexes the items by their tags and the ones of their category and subcategory
+func tagIndex(categories []*Category) TagIndex {
+	var index = make(TagIndex)
+	for _, cat := range categories {
+		for _, sub := range cat.subcategories {
+			for _, dif := range sub.difficulties {
+				for _, item := range dif.items {
+					tags := append(append(append([]string{}, cat.Tags...), sub.Tags...), item.Tags...)
+					index.add(cat.Locale, tags, Tagged{
+						Category:    cat.ID,
+						Subcategory: sub.ID,
+						Difficulty:  dif.ID,
+						Item:        item,
+					})
+				}
+			}
+		}
+	}
+	return index
+}
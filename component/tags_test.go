@@ -0,0 +1,63 @@
+package component
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestTags(t *testing.T) {
+	cat := &Category{ID: "cat", Locale: "en", Tags: []string{"Travel"}}
+	sub := &Subcategory{ID: "sub", Tags: []string{"travel", " protests"}}
+	dif := &Difficulty{ID: "beginner"}
+	a, b := &Item{ID: "a", Tags: []string{"borders"}}, &Item{ID: "b"}
+	dif.AddItem(a, b)
+	sub.AddDifficulty(dif)
+	cat.Add(sub)
+	other := &Category{ID: "other", Locale: "en"}
+	otherSub := &Subcategory{ID: "sub"}
+	otherDif := &Difficulty{ID: "beginner"}
+	c := &Item{ID: "c", Tags: []string{"Borders"}}
+	otherDif.AddItem(c)
+	otherSub.AddDifficulty(otherDif)
+	other.Add(otherSub)
+
+	index := tagIndex([]*Category{cat, other})
+	if tags := index.Tags("en"); !reflect.DeepEqual(tags, []string{"borders", "protests", "travel"}) {
+		t.Errorf("unexpected tags %v", tags)
+	}
+	var testCases = []struct {
+		tag   string
+		items []*Item
+	}{
+		{"travel", []*Item{a, b}},
+		{"PROTESTS", []*Item{a, b}},
+		{"borders", []*Item{a, c}},
+		{"missing", nil},
+	}
+	for _, tc := range testCases {
+		var items []*Item
+		for _, v := range index.Items("en", tc.tag) {
+			items = append(items, v.Item)
+		}
+		if !reflect.DeepEqual(items, tc.items) {
+			t.Errorf("%s: expected %v, got %v", tc.tag, tc.items, items)
+		}
+	}
+	if tags := index.Tags("it"); len(tags) != 0 {
+		t.Errorf("unexpected tags %v", tags)
+	}
+}
+
+func TestTagsMeta(t *testing.T) {
+	var item Item
+	contents := "[Title]: # (Title)\n[Order]: # (1)\n[Tags]: # (travel;protests)\n\nBody"
+	if err := item.SetContents(contents); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(item.Tags, []string{"travel", "protests"}) {
+		t.Errorf("unexpected tags %v", item.Tags)
+	}
+	if c := item.Contents(); c != contents {
+		t.Errorf("expected \n%q, got \n%q", contents, c)
+	}
+}
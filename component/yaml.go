@@ -0,0 +1,258 @@
+package component
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	yaml "gopkg.in/yaml.v2"
+)
+
+// Syntaxes of the metadata
+const (
+	FormatRows = "rows"
+	FormatYAML = "yaml"
+)
+
+var ErrFormat = errors.New("Invalid metadata format")
+
+const frontMatter = "---"
+
+// CheckFormat returns the syntax of the metadata, rows if empty
+func CheckFormat(format string) (string, error) {
+	switch format {
+	case "":
+		return FormatRows, nil
+	case FormatRows, FormatYAML:
+		return format, nil
+	}
+	return "", ErrFormat
+}
+
+// frontMatterer is a component that can write its metadata as YAML front matter
+type frontMatterer interface {
+	frontMatter() (string, error)
+}
+
+// Contents returns the contents of the component with the metadata in the syntax of the parser
+func (p *Parser) Contents(c Component) (string, error) {
+	if f, ok := c.(frontMatterer); ok && p.Format == FormatYAML {
+		return f.frontMatter()
+	}
+	return c.Contents(), nil
+}
+
+// Migrate converts the contents of a file to the syntax of the parser
+func (p *Parser) Migrate(path, contents string) (string, error) {
+	cmp, err := newCmp(path)
+	if err != nil {
+		return "", err
+	}
+	if _, ok := cmp.(*Asset); ok {
+		return contents, nil
+	}
+	if err := cmp.SetContents(strings.Replace(strings.TrimSpace(contents), "\r\n", "\n", -1)); err != nil {
+		return "", err
+	}
+	return p.Contents(cmp)
+}
+
+func isFrontMatter(contents string) bool {
+	return strings.HasPrefix(contents, frontMatter+"\n")
+}
+
+// readFrontMatter parses the YAML front matter and returns the rest of the contents
+func readFrontMatter(contents string) (map[string]interface{}, string, error) {
+	rest := strings.TrimPrefix(contents, frontMatter+"\n")
+	var raw, body string
+	switch {
+	case strings.HasPrefix(rest, frontMatter):
+		body = rest[len(frontMatter):]
+	default:
+		idx := strings.Index(rest, "\n"+frontMatter)
+		if idx < 0 {
+			return nil, "", fmt.Errorf("front matter: missing %q", frontMatter)
+		}
+		raw, body = rest[:idx], rest[idx+len(frontMatter)+1:]
+	}
+	if body != "" && body[0] != '\n' {
+		return nil, "", fmt.Errorf("front matter: invalid %q", frontMatter)
+	}
+	var values map[string]interface{}
+	if err := yaml.Unmarshal([]byte(raw), &values); err != nil {
+		return nil, "", fmt.Errorf("front matter: %s", err)
+	}
+	return values, strings.TrimLeft(body, "\n"), nil
+}
+
+// writeFrontMatter returns the values as YAML front matter
+func writeFrontMatter(v yaml.MapSlice) (string, error) {
+	b, err := yaml.Marshal(v)
+	if err != nil {
+		return "", fmt.Errorf("front matter: %s", err)
+	}
+	if len(v) == 0 {
+		b = nil
+	}
+	return fmt.Sprintf("%s\n%s%s", frontMatter, b, frontMatter), nil
+}
+
+// setMetaMap sets the metadata from the values, the keys are case insensitive
+// and the unknown ones are kept
+func setMetaMap(values map[string]interface{}, m meta) error {
+	var (
+		order    = m.order()
+		pointers = m.pointers()
+		index    = make(map[string]int)
+		seen     = make(map[string]bool)
+		extra    = extraOf(m)
+	)
+	for i, k := range order {
+		index[strings.ToLower(k)] = i
+	}
+	for k, v := range values {
+		i, ok := index[strings.ToLower(k)]
+		if !ok {
+			if extra != nil {
+				extra.set(k, v)
+			}
+			continue
+		}
+		if seen[order[i]] {
+			return fmt.Errorf("Duplicate %v", order[i])
+		}
+		seen[order[i]] = true
+		if err := setMapValue(pointers[i], v); err != nil {
+			return fmt.Errorf("meta %s: %s", order[i], err)
+		}
+	}
+	return checkRequired(m, seen)
+}
+
+func setMapValue(p interface{}, v interface{}) error {
+	if v == nil {
+		return nil
+	}
+	switch pointer := p.(type) {
+	case *string:
+		*pointer = extraString(v)
+	case *bool:
+		switch t := v.(type) {
+		case bool:
+			*pointer = t
+		default:
+			*pointer = fmt.Sprint(t) == "true"
+		}
+	case *int:
+		switch t := v.(type) {
+		case int:
+			*pointer = t
+		default:
+			return setMetaValue(p, fmt.Sprint(t))
+		}
+	case *float64:
+		switch t := v.(type) {
+		case int:
+			*pointer = float64(t)
+		case float64:
+			*pointer = t
+		default:
+			return setMetaValue(p, fmt.Sprint(t))
+		}
+	case *[]string:
+		switch t := v.(type) {
+		case []interface{}:
+			list := make([]string, len(t))
+			for i := range t {
+				list[i] = fmt.Sprint(t[i])
+			}
+			*pointer = list
+		default:
+			*pointer = []string{fmt.Sprint(t)}
+		}
+	case **time.Time:
+		switch t := v.(type) {
+		case time.Time:
+			*pointer = &t
+		default:
+			return setMetaValue(p, fmt.Sprint(t))
+		}
+	default:
+		return fmt.Errorf("unknown type %T", pointer)
+	}
+	return nil
+}
+
+// metaMap returns the metadata as ordered values, without empty optionals,
+// followed by the unknown ones
+func metaMap(m meta) yaml.MapSlice {
+	var (
+		order    = m.order()
+		optional = make(map[string]bool)
+		result   = make(yaml.MapSlice, 0, len(order))
+	)
+	for _, v := range m.optionals() {
+		optional[v] = true
+	}
+	for i, v := range m.values() {
+		var isZero bool
+		switch t := v.(type) {
+		case string:
+			isZero = t == ""
+		case int:
+			isZero = t == 0
+		case float64:
+			isZero = t == 0
+		case bool:
+			isZero = !t
+		case []string:
+			isZero = len(t) == 0 || len(t) == 1 && t[0] == ""
+		case *time.Time:
+			isZero = t == nil
+			v = formatTime(t)
+		}
+		if isZero && optional[order[i]] {
+			continue
+		}
+		if f, ok := v.(float64); ok && f == float64(int64(f)) {
+			v = int64(f)
+		}
+		result = append(result, yaml.MapItem{Key: order[i], Value: v})
+	}
+	if extra := extraOf(m); extra != nil {
+		result = append(result, extra.mapItems()...)
+	}
+	return result
+}
+
+// popList removes a list of maps from the values, the key is case insensitive
+func popList(values map[string]interface{}, key string) ([]map[string]interface{}, error) {
+	var raw interface{}
+	for k, v := range values {
+		if strings.EqualFold(k, key) {
+			raw = v
+			delete(values, k)
+			break
+		}
+	}
+	if raw == nil {
+		return nil, nil
+	}
+	items, ok := raw.([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("meta %s: not a list", key)
+	}
+	var list = make([]map[string]interface{}, len(items))
+	for i, item := range items {
+		m, ok := item.(map[interface{}]interface{})
+		if !ok {
+			return nil, fmt.Errorf("meta %s: not a map", key)
+		}
+		list[i] = make(map[string]interface{}, len(m))
+		for k, v := range m {
+			list[i][fmt.Sprint(k)] = v
+		}
+	}
+	return list, nil
+}
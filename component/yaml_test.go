@@ -0,0 +1,92 @@
+package component
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestFrontMatter(t *testing.T) {
+	var (
+		rows = Parser{Format: FormatRows}
+		yaml = Parser{Format: FormatYAML}
+	)
+	var testCases = []struct {
+		path string
+		rows string
+		yaml string
+	}{
+		{
+			"contents_en/cat/.metadata.md",
+			"[Name]: # (Category)\n[Order]: # (1)",
+			"---\nName: Category\nOrder: 1\n---",
+		},
+		{
+			"contents_en/cat/sub/diff/item.md",
+			"[Title]: # (Title)\n[Order]: # (2.5)\n[Status]: # (draft)\n[LastReviewed]: # (2019-01-01)\n\nSome body\n\nMore body",
+			"---\nTitle: Title\nOrder: 2.5\nStatus: draft\nLastReviewed: \"2019-01-01\"\n---\n\nSome body\n\nMore body",
+		},
+		{
+			"contents_en/cat/sub/diff/.checks.md",
+			"[ReviewEvery]: # (30)\n\n[Text]: # (First)\n[NoCheck]: # (false)\n\n[Text]: # (Second)\n[NoCheck]: # (true)",
+			"---\nReviewEvery: 30\nChecks:\n- Text: First\n  NoCheck: false\n- Text: Second\n  NoCheck: true\n---",
+		},
+		{
+			"forms_en/form.md",
+			"[Name]: # (Form)\n\n[Type]: # (screen)\n[Name]: # (Screen)\n\n[Type]: # (multiple_choice)\n[Name]: # (choice)\n[Label]: # (Label)\n[Options]: # (a;b)",
+			"---\nName: Form\nScreens:\n- Name: Screen\n  Items:\n  - Type: multiple_choice\n    Name: choice\n    Label: Label\n    Options:\n    - a\n    - b\n---",
+		},
+	}
+	for _, tc := range testCases {
+		v, err := yaml.Migrate(tc.path, tc.rows)
+		if err != nil {
+			t.Errorf("%s: %s", tc.path, err)
+			continue
+		}
+		if v != tc.yaml {
+			t.Errorf("%s: expected \n%q, got \n%q", tc.path, tc.yaml, v)
+		}
+		if v, err = rows.Migrate(tc.path, tc.yaml); err != nil {
+			t.Errorf("%s: %s", tc.path, err)
+			continue
+		}
+		if v != tc.rows {
+			t.Errorf("%s: expected \n%q, got \n%q", tc.path, tc.rows, v)
+		}
+	}
+}
+
+// badValue cannot be written as YAML
+type badValue struct{}
+
+func (badValue) MarshalYAML() (interface{}, error) { return nil, errors.New("bad value") }
+
+func TestFrontMatterError(t *testing.T) {
+	i := Item{Title: "Title", Extra: Extra{"Bad": badValue{}}}
+	if _, err := (&Parser{Format: FormatYAML}).Contents(&i); err == nil {
+		t.Error("expected error")
+	}
+}
+
+func TestFrontMatterOrder(t *testing.T) {
+	var a, b Item
+	if err := a.SetContents("[Title]: # (Title)\n[Order]: # (1)\n[ReviewEvery]: # (10)\n\nBody"); err != nil {
+		t.Fatal(err)
+	}
+	if err := b.SetContents("---\nreviewevery: 10\norder: 1\ntitle: Title\n---\nBody"); err != nil {
+		t.Fatal(err)
+	}
+	a.htmlBody, b.htmlBody = "", ""
+	if !reflect.DeepEqual(a, b) {
+		t.Errorf("expected %+v, got %+v", a, b)
+	}
+	for _, v := range []string{
+		"---\nTitle: Title\n---\nBody",
+		"---\nTitle: Title\nOrder: [1\n---\nBody",
+		"---\nTitle: Title\nOrder: 1\nBody",
+	} {
+		if err := new(Item).SetContents(v); err == nil {
+			t.Errorf("%q: expected error", v)
+		}
+	}
+}
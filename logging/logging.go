@@ -0,0 +1,208 @@
+package logging
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"runtime/debug"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Level is the severity of a message
+type Level int
+
+// Levels
+const (
+	LevelDebug Level = iota
+	LevelInfo
+	LevelWarn
+	LevelError
+)
+
+var levelNames = map[Level]string{
+	LevelDebug: "debug",
+	LevelInfo:  "info",
+	LevelWarn:  "warn",
+	LevelError: "error",
+}
+
+func (l Level) String() string { return levelNames[l] }
+
+// ParseLevel returns the level with the name, info is the default
+func ParseLevel(s string) Level {
+	for l, name := range levelNames {
+		if strings.EqualFold(s, name) {
+			return l
+		}
+	}
+	return LevelInfo
+}
+
+// Config contains the logging options
+type Config struct {
+	Level   string
+	Format  string
+	Privacy bool
+	IP      string
+}
+
+// ipMode returns how to log IPs, privacy mode drops them unless specified
+func (c *Config) ipMode() string {
+	if c.IP == "" && c.Privacy {
+		return IPNone
+	}
+	return c.IP
+}
+
+// Fields are the structured values of a message
+type Fields map[string]interface{}
+
+// Logger writes leveled messages as text or JSON
+type Logger struct {
+	mu     *sync.Mutex
+	out    io.Writer
+	name   string
+	level  Level
+	json   bool
+	config Config
+}
+
+// New returns a Logger with the name writing on out
+func New(out io.Writer, name string, c Config) *Logger {
+	return &Logger{
+		mu:     new(sync.Mutex),
+		out:    out,
+		name:   name,
+		level:  ParseLevel(c.Level),
+		json:   strings.EqualFold(c.Format, "json"),
+		config: c,
+	}
+}
+
+// Named returns a Logger with the same output and options, but another name
+func (l *Logger) Named(name string) *Logger {
+	v := *l
+	v.name = name
+	return &v
+}
+
+// Log writes the message if the level is enabled
+func (l *Logger) Log(level Level, msg string, f Fields) {
+	if level < l.level {
+		return
+	}
+	now := time.Now()
+	b := bytes.NewBuffer(nil)
+	if l.json {
+		m := make(map[string]interface{}, len(f)+4)
+		for k, v := range f {
+			m[k] = v
+		}
+		m["time"], m["level"], m["logger"], m["msg"] = now.Format(time.RFC3339), level.String(), l.name, msg
+		json.NewEncoder(b).Encode(m)
+	} else {
+		fmt.Fprintf(b, "%s [%s] %s %s", now.Format("15:04:05"), l.name, strings.ToUpper(level.String()), msg)
+		keys := make([]string, 0, len(f))
+		for k := range f {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			fmt.Fprintf(b, " %s=%v", k, f[k])
+		}
+		b.WriteByte('\n')
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.out.Write(b.Bytes())
+}
+
+// Debugf logs a formatted debug message
+func (l *Logger) Debugf(format string, v ...interface{}) {
+	l.Log(LevelDebug, fmt.Sprintf(format, v...), nil)
+}
+
+// Infof logs a formatted info message
+func (l *Logger) Infof(format string, v ...interface{}) {
+	l.Log(LevelInfo, fmt.Sprintf(format, v...), nil)
+}
+
+// Warnf logs a formatted warning message
+func (l *Logger) Warnf(format string, v ...interface{}) {
+	l.Log(LevelWarn, fmt.Sprintf(format, v...), nil)
+}
+
+// Errorf logs a formatted error message
+func (l *Logger) Errorf(format string, v ...interface{}) {
+	l.Log(LevelError, fmt.Sprintf(format, v...), nil)
+}
+
+// Middleware returns a gin handler logging every request, in privacy mode
+// query strings and user agents are dropped and IPs are dropped or truncated
+func (l *Logger) Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		c.Next()
+		status := c.Writer.Status()
+		f := Fields{
+			"method":  c.Request.Method,
+			"path":    c.Request.URL.Path,
+			"status":  status,
+			"latency": time.Since(start).String(),
+			"size":    c.Writer.Size(),
+		}
+		if ip := RedactIP(c.ClientIP(), l.config.ipMode()); ip != "" {
+			f["ip"] = ip
+		}
+		if !l.config.Privacy {
+			if q := c.Request.URL.RawQuery; q != "" {
+				f["query"] = q
+			}
+			if ua := c.Request.UserAgent(); ua != "" {
+				f["user_agent"] = ua
+			}
+		}
+		if len(c.Errors) != 0 {
+			f["errors"] = c.Errors.String()
+		}
+		level := LevelInfo
+		switch {
+		case status >= 500:
+			level = LevelError
+		case status >= 400:
+			level = LevelWarn
+		}
+		l.Log(level, "request", f)
+	}
+}
+
+// Recovery returns a gin handler that logs panics with the request method
+// and path only, never the headers, and responds with an internal error
+func (l *Logger) Recovery() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		defer func() {
+			v := recover()
+			if v == nil {
+				return
+			}
+			f := Fields{
+				"method": c.Request.Method,
+				"path":   c.Request.URL.Path,
+				"panic":  fmt.Sprint(v),
+			}
+			if l.level == LevelDebug {
+				f["stack"] = string(debug.Stack())
+			}
+			l.Log(LevelError, "panic", f)
+			c.AbortWithStatus(http.StatusInternalServerError)
+		}()
+		c.Next()
+	}
+}
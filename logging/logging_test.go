@@ -0,0 +1,74 @@
+package logging
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestPrivacy(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	var testCases = []struct {
+		config  Config
+		present []string
+		absent  []string
+	}{
+		{Config{Format: "json"}, []string{"ip", "query", "user_agent"}, nil},
+		{Config{Format: "json", Privacy: true}, nil, []string{"ip", "query", "user_agent"}},
+		{Config{Format: "json", Privacy: true, IP: "truncate"}, []string{"ip"}, []string{"query", "user_agent"}},
+		{Config{Format: "json", Level: "error"}, nil, []string{"msg"}},
+	}
+	for i, tc := range testCases {
+		var b bytes.Buffer
+		e := gin.New()
+		e.Use(New(&b, "http", tc.config).Middleware())
+		e.GET("/guide", func(*gin.Context) {})
+		r := httptest.NewRequest("GET", "/guide?q=secret", nil)
+		r.RemoteAddr, r.Header["User-Agent"] = "10.1.2.3:1234", []string{"agent"}
+		e.ServeHTTP(httptest.NewRecorder(), r)
+
+		var m map[string]interface{}
+		if b.Len() != 0 {
+			if err := json.Unmarshal(b.Bytes(), &m); err != nil {
+				t.Fatalf("%d: %s", i, err)
+			}
+		}
+		for _, k := range tc.present {
+			if _, ok := m[k]; !ok {
+				t.Errorf("%d: expected %q in %s", i, k, b.String())
+			}
+		}
+		for _, k := range tc.absent {
+			if _, ok := m[k]; ok {
+				t.Errorf("%d: unexpected %q in %s", i, k, b.String())
+			}
+		}
+		if ip, ok := m["ip"]; ok && tc.config.IP == "truncate" && ip != "10.1.2.0" {
+			t.Errorf("%d: expected truncated ip, got %v", i, ip)
+		}
+	}
+}
+
+func TestRecovery(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	var b bytes.Buffer
+	l := New(&b, "http", Config{Format: "json"})
+	e := gin.New()
+	e.Use(l.Middleware(), l.Recovery())
+	e.GET("/panic", func(*gin.Context) { panic("boom") })
+	r := httptest.NewRequest("GET", "/panic", nil)
+	r.Header.Set("Authorization", "Bearer secret-token")
+	r.Header.Set("Cookie", "github-auth=secret-session")
+	w := httptest.NewRecorder()
+	e.ServeHTTP(w, r)
+	if w.Code != 500 {
+		t.Errorf("expected 500, got %d", w.Code)
+	}
+	if s := b.String(); !strings.Contains(s, "boom") || strings.Contains(s, "secret") {
+		t.Errorf("unexpected log %s", s)
+	}
+}
@@ -0,0 +1,30 @@
+package logging
+
+import "net"
+
+// IP modes
+const (
+	IPFull     = "full"
+	IPTruncate = "truncate"
+	IPNone     = "none"
+)
+
+// RedactIP applies the mode to the address: truncate keeps the network
+// part (/24 for IPv4, /48 for IPv6) and none drops it
+func RedactIP(ip, mode string) string {
+	switch mode {
+	case IPNone:
+		return ""
+	case IPTruncate:
+		v := net.ParseIP(ip)
+		if v == nil {
+			return ""
+		}
+		if v4 := v.To4(); v4 != nil {
+			return v4.Mask(net.CIDRMask(24, 32)).String()
+		}
+		return v.Mask(net.CIDRMask(48, 128)).String()
+	default:
+		return ip
+	}
+}
@@ -6,8 +6,10 @@ import (
 	"github.com/google/go-github/github"
 )
 
-// User is the model used for cookies
+// User is the model used for cookies, ID is the identity of the user
+// namespaced by provider, like github:login or oidc:sub
 type User struct {
+	ID    string `json:"id,omitempty"`
 	Login string `json:"login"`
 	Name  string `json:"name"`
 	Email string `json:"email"`
@@ -0,0 +1,166 @@
+package repo
+
+import (
+	"context"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/go-github/github"
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/jws"
+	"gopkg.in/src-d/go-git.v4/plumbing/transport"
+	githttp "gopkg.in/src-d/go-git.v4/plumbing/transport/http"
+)
+
+var ErrAppKey = errors.New("invalid app private key")
+
+// Credentials return the authentication for git operations, nil is anonymous
+type Credentials func() (transport.AuthMethod, error)
+
+// AppConf contains the info about a Github App, if the installation
+// is not specified the one of the repository is used
+type AppConf struct {
+	ID           int64
+	Installation int64
+	PrivateKey   string
+}
+
+// permissionDuration is how long the permission of a user is cached
+const permissionDuration = 5 * time.Minute
+
+// permission is the cached write permission of a user
+type permission struct {
+	write   bool
+	expires time.Time
+}
+
+// App authenticates as a Github App installation, refreshing its token
+type App struct {
+	sync.Mutex
+	conf  AppConf
+	key   *rsa.PrivateKey
+	owner string
+	name  string
+	token *oauth2.Token
+	perms map[string]permission
+	api   *url.URL
+}
+
+// NewApp loads the private key of the App
+func NewApp(c AppConf, owner, name string) (*App, error) {
+	b, err := ioutil.ReadFile(c.PrivateKey)
+	if err != nil {
+		return nil, err
+	}
+	block, _ := pem.Decode(b)
+	if block == nil {
+		return nil, ErrAppKey
+	}
+	key, err := x509.ParsePKCS1PrivateKey(block.Bytes)
+	if err != nil {
+		k, err2 := x509.ParsePKCS8PrivateKey(block.Bytes)
+		if err2 != nil {
+			return nil, fmt.Errorf("%s: %s", ErrAppKey, err)
+		}
+		var ok bool
+		if key, ok = k.(*rsa.PrivateKey); !ok {
+			return nil, ErrAppKey
+		}
+	}
+	return &App{conf: c, key: key, owner: owner, name: name, perms: make(map[string]permission)}, nil
+}
+
+// jwt returns the token used to authenticate as the App
+func (a *App) jwt() (string, error) {
+	now := time.Now()
+	return jws.Encode(&jws.Header{Algorithm: "RS256", Typ: "JWT"}, &jws.ClaimSet{
+		Iss: strconv.FormatInt(a.conf.ID, 10),
+		Iat: now.Add(-time.Minute).Unix(),
+		Exp: now.Add(9 * time.Minute).Unix(),
+	}, a.key)
+}
+
+// Token returns the installation token, creating a new one when it expires
+func (a *App) Token() (*oauth2.Token, error) {
+	a.Lock()
+	defer a.Unlock()
+	if a.token != nil && a.token.Expiry.After(time.Now().Add(time.Minute)) {
+		return a.token, nil
+	}
+	jwt, err := a.jwt()
+	if err != nil {
+		return nil, err
+	}
+	ctx := context.Background()
+	client := github.NewClient(oauth2.NewClient(ctx, oauth2.StaticTokenSource(&oauth2.Token{AccessToken: jwt})))
+	if a.conf.Installation == 0 {
+		inst, _, err := client.Apps.FindRepositoryInstallation(ctx, a.owner, a.name)
+		if err != nil {
+			return nil, fmt.Errorf("app installation: %s", err)
+		}
+		a.conf.Installation = inst.GetID()
+	}
+	t, _, err := client.Apps.CreateInstallationToken(ctx, a.conf.Installation)
+	if err != nil {
+		return nil, fmt.Errorf("app token: %s", err)
+	}
+	a.token = &oauth2.Token{AccessToken: t.GetToken(), TokenType: "token", Expiry: t.GetExpiresAt()}
+	return a.token, nil
+}
+
+// Credentials returns the installation token as git credentials
+func (a *App) Credentials() (transport.AuthMethod, error) {
+	t, err := a.Token()
+	if err != nil {
+		return nil, err
+	}
+	return &githttp.BasicAuth{Username: "x-access-token", Password: t.AccessToken}, nil
+}
+
+// client returns a Github client authenticated as the installation
+func (a *App) client() *github.Client {
+	c := github.NewClient(oauth2.NewClient(context.Background(), a))
+	if a.api != nil {
+		c.BaseURL = a.api
+	}
+	return c
+}
+
+// CanWrite tells if the Github user has write or admin permission on the
+// repository, the result is cached for a few minutes
+func (a *App) CanWrite(login string) (bool, error) {
+	key := strings.ToLower(login)
+	a.Lock()
+	p, ok := a.perms[key]
+	a.Unlock()
+	if ok && time.Now().Before(p.expires) {
+		return p.write, nil
+	}
+	level, _, err := a.client().Repositories.GetPermissionLevel(context.Background(), a.owner, a.name, login)
+	switch re, _ := err.(*github.ErrorResponse); {
+	case re != nil && re.Response.StatusCode == http.StatusNotFound:
+		// not a collaborator
+	case err != nil:
+		return false, fmt.Errorf("app permission: %s", err)
+	default:
+		switch level.GetPermission() {
+		case "admin", "write":
+			p.write = true
+		}
+	}
+	p.expires = time.Now().Add(permissionDuration)
+	a.Lock()
+	a.perms[key] = p
+	a.Unlock()
+	return p.write, nil
+}
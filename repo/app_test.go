@@ -0,0 +1,74 @@
+package repo
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"golang.org/x/oauth2"
+
+	"github.com/securityfirst/tent/component"
+	"github.com/securityfirst/tent/models"
+)
+
+func TestAppPermission(t *testing.T) {
+	var calls int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if r.Header.Get("Authorization") != "Bearer installation" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		const prefix, suffix = "/repos/owner/name/collaborators/", "/permission"
+		login := strings.ToLower(strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, prefix), suffix))
+		permission, ok := map[string]string{"editor": "write", "owner": "admin", "reader": "read"}[login]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			json.NewEncoder(w).Encode(map[string]string{"message": "Not Found"})
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]string{"permission": permission})
+	}))
+	defer srv.Close()
+	api, _ := url.Parse(srv.URL + "/")
+	app := &App{
+		owner: "owner",
+		name:  "name",
+		token: &oauth2.Token{AccessToken: "installation", Expiry: time.Now().Add(time.Hour)},
+		perms: make(map[string]permission),
+		api:   api,
+	}
+	r := Repo{app: app}
+	var testCases = []struct {
+		user models.User
+		err  error
+	}{
+		{models.User{ID: "github:editor", Login: "editor"}, nil},
+		{models.User{ID: "github:owner", Login: "Owner"}, nil},
+		{models.User{ID: "github:reader", Login: "reader"}, ErrPermission},
+		{models.User{ID: "github:stranger", Login: "stranger"}, ErrPermission},
+		{models.User{ID: "oidc:1234", Login: "stranger"}, nil},
+	}
+	for _, tc := range testCases {
+		if err := r.checkWrite(tc.user); err != tc.err {
+			t.Errorf("%s: expected %v, got %v", tc.user.ID, tc.err, err)
+		}
+	}
+	n := calls
+	if err := r.checkWrite(models.User{ID: "github:reader", Login: "reader"}); err != ErrPermission || calls != n {
+		t.Errorf("expected cached permission, got %v after %d calls", err, calls-n)
+	}
+	// a logged in user without permission cannot write
+	stranger := models.User{ID: "github:stranger", Login: "stranger"}
+	form := &component.Form{ID: "form", Locale: "en"}
+	if err := r.Update(form, stranger, "", Commit{}); err != ErrPermission {
+		t.Errorf("expected %v, got %v", ErrPermission, err)
+	}
+	if err := r.Move(component.Ref{Category: "a"}, component.Ref{Category: "b"}, stranger, "", Commit{}); err != ErrPermission {
+		t.Errorf("expected %v, got %v", ErrPermission, err)
+	}
+}
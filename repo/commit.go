@@ -0,0 +1,90 @@
+package repo
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"text/template"
+
+	"github.com/securityfirst/tent/models"
+)
+
+// Commit contains the optional details of a change
+type Commit struct {
+	Message   string
+	CoAuthors []models.User
+}
+
+// CommitConf contains the default messages, by action, and the signing options
+type CommitConf struct {
+	Messages  map[string]string
+	Committer models.User
+	Sign      SignConf
+}
+
+// messageData is the data available to the message templates
+type messageData struct {
+	Action string
+	Path   string
+	User   models.User
+}
+
+const defaultMessage = "{{.Action}} {{.Path}}"
+
+// SetCommitConf parses the messages templates and loads the signing key
+func (r *Repo) SetCommitConf(c CommitConf) error {
+	templates := make(map[int]*template.Template)
+	for action, name := range commitMsg {
+		text, ok := c.Messages[strings.ToLower(name)]
+		if !ok {
+			text = defaultMessage
+		}
+		t, err := template.New(name).Parse(text)
+		if err != nil {
+			return fmt.Errorf("message %s: %s", name, err)
+		}
+		templates[action] = t
+	}
+	s, err := c.Sign.signer()
+	if err != nil {
+		return err
+	}
+	r.Lock()
+	defer r.Unlock()
+	r.messages, r.signer, r.committer = templates, s, c.Committer
+	return nil
+}
+
+// message returns the commit message, with co-authors as trailers
+func (r *Repo) message(action int, file string, u models.User, commit Commit) (string, error) {
+	msg := strings.TrimSpace(commit.Message)
+	if msg == "" {
+		b := bytes.NewBuffer(nil)
+		data := messageData{Action: commitMsg[action], Path: file, User: u}
+		if t, ok := r.messages[action]; ok {
+			if err := t.Execute(b, data); err != nil {
+				return "", err
+			}
+		} else {
+			fmt.Fprintf(b, "%s %s", data.Action, data.Path)
+		}
+		msg = b.String()
+	}
+	if len(commit.CoAuthors) != 0 {
+		msg += "\n"
+		for _, a := range commit.CoAuthors {
+			msg += fmt.Sprintf("\nCo-authored-by: %s <%s>", coAuthorName(a), a.Email)
+		}
+	}
+	return msg, nil
+}
+
+// coAuthorName returns the name of the co-author, or its login or email if empty
+func coAuthorName(u models.User) string {
+	for _, v := range []string{u.Name, u.Login, u.Email} {
+		if v = strings.TrimSpace(v); v != "" {
+			return v
+		}
+	}
+	return "unknown"
+}
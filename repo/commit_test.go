@@ -0,0 +1,43 @@
+package repo
+
+import (
+	"testing"
+
+	"github.com/securityfirst/tent/models"
+)
+
+func TestMessage(t *testing.T) {
+	var r Repo
+	err := r.SetCommitConf(CommitConf{Messages: map[string]string{
+		"create": "Add {{.Path}} by {{.User.Login}}",
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	u := models.User{Login: "editor"}
+	var testCases = []struct {
+		action   int
+		commit   Commit
+		expected string
+	}{
+		{actionCreate, Commit{}, "Add a.md by editor"},
+		{actionUpdate, Commit{}, "Update a.md"},
+		{actionUpdate, Commit{Message: " Fix typo "}, "Fix typo"},
+		{actionDelete, Commit{CoAuthors: []models.User{
+			{Name: "Jane", Email: "jane@tent.org"},
+			{Email: "john@tent.org"},
+		}}, "Delete a.md\n\nCo-authored-by: Jane <jane@tent.org>\nCo-authored-by: john@tent.org <john@tent.org>"},
+	}
+	for _, tc := range testCases {
+		msg, err := r.message(tc.action, "a.md", u, tc.commit)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if msg != tc.expected {
+			t.Errorf("expected %q, got %q", tc.expected, msg)
+		}
+	}
+	if err := r.SetCommitConf(CommitConf{Messages: map[string]string{"update": "{{.Path"}}); err == nil {
+		t.Error("expected template error")
+	}
+}
@@ -0,0 +1,114 @@
+package repo
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+
+	"gopkg.in/src-d/go-git.v4/plumbing/transport"
+	"gopkg.in/src-d/go-git.v4/plumbing/transport/http"
+	"gopkg.in/src-d/go-git.v4/plumbing/transport/ssh"
+)
+
+const githubHost = "github.com"
+
+var ErrCredentials = errors.New("invalid credentials")
+
+// CredentialsConf contains the credentials used to access a private repository,
+// only one of token, SSH key and netrc file can be used. They are used for pushes
+// only if Push is set, otherwise the token of the user is.
+type CredentialsConf struct {
+	Token      string
+	SSHKey     string
+	Passphrase string
+	KnownHosts string
+	Netrc      string
+	Push       bool
+}
+
+// Credentials returns the configured credentials, nil if none
+func (c *CredentialsConf) Credentials() (Credentials, error) {
+	var n int
+	for _, v := range []string{c.Token, c.SSHKey, c.Netrc} {
+		if v != "" {
+			n++
+		}
+	}
+	if n > 1 {
+		return nil, fmt.Errorf("%s: use only one of token, ssh key and netrc", ErrCredentials)
+	}
+	var auth transport.AuthMethod
+	switch {
+	case c.Token != "":
+		auth = &http.BasicAuth{Username: "x-access-token", Password: c.Token}
+	case c.SSHKey != "":
+		keys, err := ssh.NewPublicKeysFromFile("git", c.SSHKey, c.Passphrase)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %s", ErrCredentials, err)
+		}
+		if c.KnownHosts != "" {
+			if keys.HostKeyCallback, err = ssh.NewKnownHostsCallback(c.KnownHosts); err != nil {
+				return nil, fmt.Errorf("%s: %s", ErrCredentials, err)
+			}
+		}
+		auth = keys
+	case c.Netrc != "":
+		login, password, err := readNetrc(c.Netrc, githubHost)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %s", ErrCredentials, err)
+		}
+		auth = &http.BasicAuth{Username: login, Password: password}
+	default:
+		return nil, nil
+	}
+	return func() (transport.AuthMethod, error) { return auth, nil }, nil
+}
+
+// readNetrc returns login and password for the host, or the default ones
+func readNetrc(path, host string) (login, password string, err error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", "", err
+	}
+	defer f.Close()
+	s := bufio.NewScanner(f)
+	s.Split(bufio.ScanWords)
+	var (
+		match, found bool
+		next         = func() string { s.Scan(); return s.Text() }
+	)
+	for s.Scan() {
+		switch s.Text() {
+		case "machine":
+			if found {
+				return login, password, nil
+			}
+			match = next() == host
+		case "default":
+			if found {
+				return login, password, nil
+			}
+			match = true
+		case "login":
+			if v := next(); match {
+				login, found = v, true
+			}
+		case "password":
+			if v := next(); match {
+				password, found = v, true
+			}
+		}
+	}
+	if err := s.Err(); err != nil {
+		return "", "", err
+	}
+	if !found {
+		return "", "", fmt.Errorf("no entry for %s in %s", host, path)
+	}
+	return login, password, nil
+}
+
+func sshAddress(owner, name string) string {
+	return fmt.Sprintf("git@%s:%s/%s.git", githubHost, owner, name)
+}
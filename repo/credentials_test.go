@@ -0,0 +1,67 @@
+package repo
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"gopkg.in/src-d/go-git.v4/plumbing/transport"
+	"gopkg.in/src-d/go-git.v4/plumbing/transport/http"
+)
+
+func TestReadNetrc(t *testing.T) {
+	dir, err := ioutil.TempDir("", "netrc")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	var testCases = []struct {
+		netrc    string
+		login    string
+		password string
+		valid    bool
+	}{
+		{"machine github.com login user password secret", "user", "secret", true},
+		{"machine gitlab.com\n\tlogin other\n\tpassword x\nmachine github.com\n\tlogin user\n\tpassword secret\n", "user", "secret", true},
+		{"machine github.com login user password secret\nmachine gitlab.com login other password x", "user", "secret", true},
+		{"machine gitlab.com login other password x\ndefault login anon password y", "anon", "y", true},
+		{"machine github.com password secret", "", "secret", true},
+		{"machine gitlab.com login other password x", "", "", false},
+		{"", "", "", false},
+	}
+	for i, tc := range testCases {
+		path := filepath.Join(dir, "netrc")
+		if err := ioutil.WriteFile(path, []byte(tc.netrc), 0600); err != nil {
+			t.Fatal(err)
+		}
+		login, password, err := readNetrc(path, githubHost)
+		if (err == nil) != tc.valid {
+			t.Errorf("%d: unexpected error %v", i, err)
+			continue
+		}
+		if login != tc.login || password != tc.password {
+			t.Errorf("%d: expected %s:%s, got %s:%s", i, tc.login, tc.password, login, password)
+		}
+	}
+	if _, _, err := readNetrc(filepath.Join(dir, "missing"), githubHost); err == nil {
+		t.Error("expected error for missing file")
+	}
+}
+
+func TestPushAuth(t *testing.T) {
+	creds := func() (transport.AuthMethod, error) {
+		return &http.BasicAuth{Username: "deploy", Password: "key"}, nil
+	}
+	r := Repo{owner: "owner", creds: creds}
+	if a, err := r.pushAuth("user-token"); err != nil || a.(*http.BasicAuth).Password != "user-token" {
+		t.Errorf("expected user token, got %v: %v", a, err)
+	}
+	if _, err := r.pushAuth(""); err != ErrCredentials {
+		t.Errorf("expected %v, got %v", ErrCredentials, err)
+	}
+	r.SetPushCredentials(true)
+	if a, err := r.pushAuth("user-token"); err != nil || a.(*http.BasicAuth).Password != "key" {
+		t.Errorf("expected repository credentials, got %v: %v", a, err)
+	}
+}
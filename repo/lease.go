@@ -0,0 +1,189 @@
+package repo
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/securityfirst/tent/models"
+)
+
+var (
+	ErrLocked = errors.New("component locked")
+	ErrLease  = errors.New("lease not found")
+)
+
+const defaultLease = 15 * time.Minute
+
+// LeaseConf contains the options of the editing leases, the file is optional
+type LeaseConf struct {
+	File     string
+	Duration time.Duration
+}
+
+// Lease is a time-limited lock on a component held by a user, identified by ID
+// so that each API key holds its own leases
+type Lease struct {
+	Path     string    `json:"path"`
+	ID       string    `json:"id,omitempty"`
+	Login    string    `json:"login"`
+	Name     string    `json:"name"`
+	Acquired time.Time `json:"acquired"`
+	Expires  time.Time `json:"expires"`
+}
+
+// Expired tells if the lease is no longer valid
+func (l *Lease) Expired() bool { return time.Now().After(l.Expires) }
+
+func (l *Lease) heldBy(u models.User) bool {
+	if l.ID == "" || u.ID == "" {
+		return l.ID == u.ID && strings.EqualFold(l.Login, u.Login)
+	}
+	return l.ID == u.ID
+}
+
+// Leases keeps the leases in memory, saving them in a file if specified
+type Leases struct {
+	sync.Mutex
+	path     string
+	duration time.Duration
+	leases   map[string]*Lease
+}
+
+// OpenLeases loads the leases from the file, if any, discarding the expired ones
+func OpenLeases(c LeaseConf) (*Leases, error) {
+	l := Leases{path: c.File, duration: c.Duration, leases: make(map[string]*Lease)}
+	if l.duration <= 0 {
+		l.duration = defaultLease
+	}
+	if l.path == "" {
+		return &l, nil
+	}
+	f, err := os.Open(l.path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return &l, nil
+		}
+		return nil, err
+	}
+	defer f.Close()
+	var list []*Lease
+	if err := json.NewDecoder(f).Decode(&list); err != nil {
+		return nil, err
+	}
+	for _, v := range list {
+		if !v.Expired() {
+			l.leases[v.Path] = v
+		}
+	}
+	return &l, nil
+}
+
+// Acquire creates or renews the lease of the user, a zero duration uses the default
+// one, that is also the maximum
+func (l *Leases) Acquire(path string, u models.User, d time.Duration) (*Lease, error) {
+	l.Lock()
+	defer l.Unlock()
+	if v := l.get(path); v != nil && !v.heldBy(u) {
+		return v, ErrLocked
+	}
+	if d <= 0 || d > l.duration {
+		d = l.duration
+	}
+	now := time.Now()
+	v := &Lease{Path: path, ID: u.ID, Login: u.Login, Name: u.Name, Acquired: now, Expires: now.Add(d)}
+	old := l.leases[path]
+	l.leases[path] = v
+	if err := l.save(); err != nil {
+		l.restore(path, old)
+		return nil, err
+	}
+	return v, nil
+}
+
+// Release removes the lease of the user
+func (l *Leases) Release(path string, u models.User) error {
+	l.Lock()
+	defer l.Unlock()
+	v := l.get(path)
+	if v == nil {
+		return ErrLease
+	}
+	if !v.heldBy(u) {
+		return ErrLocked
+	}
+	delete(l.leases, path)
+	if err := l.save(); err != nil {
+		l.leases[path] = v
+		return err
+	}
+	return nil
+}
+
+// Get returns the valid lease of a component, if any
+func (l *Leases) Get(path string) *Lease {
+	l.Lock()
+	defer l.Unlock()
+	return l.get(path)
+}
+
+// Check returns the lease and ErrLocked if the component is leased by someone else
+func (l *Leases) Check(path string, u models.User) (*Lease, error) {
+	v := l.Get(path)
+	if v != nil && !v.heldBy(u) {
+		return v, ErrLocked
+	}
+	return v, nil
+}
+
+func (l *Leases) get(path string) *Lease {
+	v, ok := l.leases[path]
+	if !ok {
+		return nil
+	}
+	if v.Expired() {
+		delete(l.leases, path)
+		return nil
+	}
+	return v
+}
+
+func (l *Leases) restore(path string, v *Lease) {
+	if v == nil {
+		delete(l.leases, path)
+		return
+	}
+	l.leases[path] = v
+}
+
+func (l *Leases) save() error {
+	if l.path == "" {
+		return nil
+	}
+	var list = make([]*Lease, 0, len(l.leases))
+	for _, v := range l.leases {
+		if !v.Expired() {
+			list = append(list, v)
+		}
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Path < list[j].Path })
+	tmp := l.path + ".tmp"
+	f, err := os.OpenFile(tmp, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
+	if err != nil {
+		return err
+	}
+	e := json.NewEncoder(f)
+	e.SetIndent("", "\t")
+	if err := e.Encode(list); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp, l.path)
+}
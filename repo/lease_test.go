@@ -0,0 +1,84 @@
+package repo
+
+import (
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/securityfirst/tent/component"
+	"github.com/securityfirst/tent/models"
+)
+
+func TestLeases(t *testing.T) {
+	l, err := OpenLeases(LeaseConf{Duration: time.Minute})
+	if err != nil {
+		t.Fatal(err)
+	}
+	const path = "forms_en/form.md"
+	var (
+		bot1 = models.User{ID: "key:a", Login: "tent-bot"}
+		bot2 = models.User{ID: "key:b", Login: "tent-bot"}
+	)
+	if _, err := l.Acquire(path, bot1, time.Hour); err != nil {
+		t.Fatal(err)
+	}
+	if v := l.Get(path); v == nil || v.ID != bot1.ID || v.Expires.Sub(v.Acquired) != time.Minute {
+		t.Errorf("unexpected lease %+v", v)
+	}
+	if _, err := l.Check(path, bot2); err != ErrLocked {
+		t.Errorf("expected %v for another key, got %v", ErrLocked, err)
+	}
+	if err := l.Release(path, bot2); err != ErrLocked {
+		t.Errorf("expected %v, got %v", ErrLocked, err)
+	}
+	if _, err := l.Check(path, bot1); err != nil {
+		t.Errorf("unexpected error %v", err)
+	}
+	if err := l.Release(path, bot1); err != nil {
+		t.Fatal(err)
+	}
+	if err := l.Release(path, bot1); err != ErrLease {
+		t.Errorf("expected %v, got %v", ErrLease, err)
+	}
+}
+
+func TestShowLease(t *testing.T) {
+	form := component.Form{ID: "form", Locale: "en", Name: "Form"}
+	r, dir := newTestRepo(t, map[string]string{form.Path(): form.Contents()})
+	defer os.RemoveAll(dir)
+	l, err := OpenLeases(LeaseConf{Duration: time.Minute})
+	if err != nil {
+		t.Fatal(err)
+	}
+	r.SetLeases(l)
+	if _, err := l.Acquire(form.Path(), testEditor, 0); err != nil {
+		t.Fatal(err)
+	}
+	h := r.Handler()
+	for _, tc := range []struct {
+		user  *models.User
+		lease bool
+	}{
+		{nil, false},
+		{&testPublisher, true},
+	} {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		if tc.user != nil {
+			c.Set("user", *tc.user)
+		}
+		c.Set("locale", "en")
+		c.Set("form", r.Form("form", "en"))
+		h.Show(c)
+		if lease := strings.Contains(w.Body.String(), `"lease"`); lease != tc.lease {
+			t.Errorf("%v: expected lease %v, got %s", tc.user, tc.lease, w.Body)
+		}
+		if !tc.lease && strings.Contains(w.Body.String(), testEditor.Login) {
+			t.Errorf("%v: unexpected editor in %s", tc.user, w.Body)
+		}
+	}
+}
@@ -0,0 +1,145 @@
+package repo
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+
+	"github.com/securityfirst/tent/component"
+	"github.com/securityfirst/tent/models"
+)
+
+var (
+	ErrMove   = errors.New("Invalid move")
+	ErrTarget = errors.New("Target parent not found")
+)
+
+// localeDir matches the root directories of the contents
+var localeDir = regexp.MustCompile("^contents(_[a-z]{2})?$")
+
+// Move relocates the component with all its children to the target, in every
+// locale, and rewrites the references to the items moved, in a single commit
+func (r *Repo) Move(from, to component.Ref, u models.User, token string, info Commit) error {
+	level := from.Level()
+	if level == 0 || to.Level() != level || from == to {
+		return ErrMove
+	}
+	if err := r.checkWrite(u); err != nil {
+		return err
+	}
+	changes, err := r.moveChanges(from, to)
+	if err != nil {
+		return err
+	}
+	if r.leases != nil {
+		for _, ch := range changes {
+			if _, err := r.leases.Check(ch.Path, u); err != nil {
+				return err
+			}
+		}
+	}
+	msg, err := r.message(actionMove, fmt.Sprintf("%s to %s", from.Prefix(), to.Prefix()), u, info)
+	if err != nil {
+		return err
+	}
+	if err := r.push(changes, msg, u, token); err != nil {
+		return err
+	}
+	go r.Pull()
+	return nil
+}
+
+// movedPath returns the path of the file of the component under from, once moved to the target
+func movedPath(path string, from, to component.Ref) string {
+	src, dst := "/"+from.Prefix(), "/"+to.Prefix()
+	if i := strings.Index(path, "/"); i != -1 && strings.HasPrefix(path[i:], src) {
+		return path[:i] + dst + path[i+len(src):]
+	}
+	return path
+}
+
+// moveChanges returns the changes that move the files under from to the target
+func (r *Repo) moveChanges(from, to component.Ref) ([]change, error) {
+	r.RLock()
+	defer r.RUnlock()
+	if r.commit == nil {
+		return nil, ErrNotReady
+	}
+	tree, err := r.commit.Tree()
+	if err != nil {
+		return nil, err
+	}
+	var (
+		level   = from.Level()
+		src     = strings.Split(from.Prefix(), "/")
+		dst     = strings.Split(to.Prefix(), "/")
+		files   = make(map[string]bool)
+		dirs    = make(map[string]bool)
+		moved   []change
+		changes []change
+	)
+	if level == 4 {
+		src[3], dst[3] = src[3]+".md", dst[3]+".md"
+	}
+	for iter := tree.Files(); ; {
+		f, err := iter.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		parts := strings.Split(f.Name, "/")
+		if !localeDir.MatchString(parts[0]) {
+			continue
+		}
+		files[f.Name] = true
+		for i := 1; i < len(parts); i++ {
+			dirs[strings.Join(parts[:i], "/")] = true
+		}
+		contents, err := f.Contents()
+		if err != nil {
+			return nil, err
+		}
+		updated := component.MoveRefs(contents, from, to)
+		if len(parts) <= level || !equalParts(parts[1:level+1], src) {
+			if updated != contents {
+				changes = append(changes, change{Path: f.Name, SHA: f.Hash.String(), Contents: strPtr(updated)})
+			}
+			continue
+		}
+		target := strings.Join(append(append([]string{parts[0]}, dst...), parts[level+1:]...), "/")
+		moved = append(moved,
+			change{Path: f.Name, SHA: f.Hash.String()},
+			change{Path: target, Contents: strPtr(updated), Create: true},
+		)
+	}
+	if len(moved) == 0 {
+		return nil, ErrNotFound
+	}
+	for i := 1; i < len(moved); i += 2 {
+		p := moved[i].Path
+		if files[p] {
+			return nil, ErrExists
+		}
+		parent := strings.Join(strings.Split(p, "/")[:level], "/")
+		if level > 1 && !dirs[parent] {
+			return nil, ErrTarget
+		}
+	}
+	return append(moved, changes...), nil
+}
+
+func equalParts(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,100 @@
+package repo
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/securityfirst/tent/component"
+	"github.com/securityfirst/tent/models"
+	"gopkg.in/src-d/go-git.v4/plumbing/object"
+)
+
+func TestMove(t *testing.T) {
+	r, dir := newTestRepo(t, map[string]string{
+		"contents_en/a/.metadata.md":     "[Name]: # (A)\n[Order]: # (1)",
+		"contents_en/a/s/.metadata.md":   "[Name]: # (S)\n[Order]: # (1)",
+		"contents_en/a/s/d/.metadata.md": "[Descr]: # (D)\n[Order]: # (1)",
+		"contents_en/a/s/d/i.md":         "[Title]: # (I)\n[Order]: # (1)\n\nSee tent://a/s/d/j",
+		"contents_en/a/s/d/j.md":         "[Title]: # (J)\n[Order]: # (2)\n\nBody",
+		"contents_en/b/.metadata.md":     "[Name]: # (B)\n[Order]: # (2)",
+		"contents_en/b/s/.metadata.md":   "[Name]: # (S)\n[Order]: # (1)",
+		"contents_it/a/.metadata.md":     "[Name]: # (A)\n[Order]: # (1)",
+		"contents_it/a/s/d/i.md":         "[Title]: # (I)\n[Order]: # (1)\n\nBody",
+		"contents_it/b/.metadata.md":     "[Name]: # (B)\n[Order]: # (2)",
+		"contents_it/c/x/d/k.md":         "[Title]: # (K)\n[Order]: # (1)\n\nSee tent://a/s/d/i",
+	})
+	defer os.RemoveAll(dir)
+	var (
+		u     = models.User{ID: "github:editor", Login: "editor", Name: "Editor", Email: "editor@tent.org"}
+		other = models.User{ID: "github:other", Login: "other"}
+		from  = component.Ref{Category: "a", Subcategory: "s"}
+	)
+	for _, tc := range []struct {
+		to  component.Ref
+		err error
+	}{
+		{component.Ref{Category: "b", Subcategory: "s"}, ErrExists},
+		{component.Ref{Category: "z", Subcategory: "s"}, ErrTarget},
+		{component.Ref{Category: "a"}, ErrMove},
+	} {
+		if err := r.Move(from, tc.to, u, "", Commit{}); err != tc.err {
+			t.Errorf("%s: expected %v, got %v", tc.to.Prefix(), tc.err, err)
+		}
+	}
+
+	to := component.Ref{Category: "b", Subcategory: "t"}
+	leases, err := OpenLeases(LeaseConf{Duration: time.Minute})
+	if err != nil {
+		t.Fatal(err)
+	}
+	r.SetLeases(leases)
+	for _, path := range []string{"contents_it/b/t/d/i.md", "contents_it/c/x/d/k.md"} {
+		if _, err := leases.Acquire(path, other, time.Minute); err != nil {
+			t.Fatal(err)
+		}
+		if err := r.Move(from, to, u, "", Commit{}); err != ErrLocked {
+			t.Errorf("%s leased: expected %v, got %v", path, ErrLocked, err)
+		}
+		if err := leases.Release(path, other); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := r.Move(from, to, u, "", Commit{}); err != nil {
+		t.Fatal(err)
+	}
+	for name, contents := range map[string]string{
+		"contents_en/b/t/d/i.md": "[Title]: # (I)\n[Order]: # (1)\n\nSee tent://b/t/d/j",
+		"contents_en/b/t/d/j.md": "[Title]: # (J)\n[Order]: # (2)\n\nBody",
+		"contents_it/b/t/d/i.md": "[Title]: # (I)\n[Order]: # (1)\n\nBody",
+		"contents_it/c/x/d/k.md": "[Title]: # (K)\n[Order]: # (1)\n\nSee tent://b/t/d/i",
+	} {
+		if s, err := headFile(t, dir, name); err != nil || s != contents {
+			t.Errorf("%s: expected %q, got %q: %v", name, contents, s, err)
+		}
+	}
+	for _, name := range []string{"contents_en/a/s/d/i.md", "contents_it/a/s/d/i.md"} {
+		if _, err := headFile(t, dir, name); err != object.ErrFileNotFound {
+			t.Errorf("%s: expected moved file, got %v", name, err)
+		}
+	}
+}
+
+func TestMovedPath(t *testing.T) {
+	for _, tc := range []struct {
+		path     string
+		from, to component.Ref
+		want     string
+	}{
+		{"contents_en/a/.metadata.md", component.Ref{Category: "a"}, component.Ref{Category: "b"}, "contents_en/b/.metadata.md"},
+		{"contents_it/a/s/d/i.md", component.Ref{Category: "a", Subcategory: "s", Difficulty: "d", Item: "i"},
+			component.Ref{Category: "b", Subcategory: "t", Difficulty: "d", Item: "i"}, "contents_it/b/t/d/i.md"},
+		{"contents_en/a/s/.metadata.md", component.Ref{Category: "a", Subcategory: "s"},
+			component.Ref{Category: "a", Subcategory: "t"}, "contents_en/a/t/.metadata.md"},
+	} {
+		if got := movedPath(tc.path, tc.from, tc.to); got != tc.want {
+			t.Errorf("%s: expected %s, got %s", tc.path, tc.want, got)
+		}
+	}
+}
@@ -0,0 +1,191 @@
+package repo
+
+import (
+	"errors"
+
+	"github.com/securityfirst/tent/component"
+	"github.com/securityfirst/tent/models"
+)
+
+var ErrOrder = errors.New("Invalid order")
+
+// orderable is a component sorted by its Order
+type orderable interface {
+	component.Component
+	id() string
+	order() float64
+	withOrder(v float64) component.Component
+}
+
+type (
+	orderCat  struct{ *component.Category }
+	orderSub  struct{ *component.Subcategory }
+	orderDiff struct{ *component.Difficulty }
+	orderItem struct{ *component.Item }
+)
+
+func (o orderCat) id() string  { return o.ID }
+func (o orderSub) id() string  { return o.ID }
+func (o orderDiff) id() string { return o.ID }
+func (o orderItem) id() string { return o.ID }
+
+func (o orderCat) order() float64  { return o.Order }
+func (o orderSub) order() float64  { return o.Order }
+func (o orderDiff) order() float64 { return o.Order }
+func (o orderItem) order() float64 { return o.Order }
+
+func (o orderCat) withOrder(v float64) component.Component {
+	c := *o.Category
+	c.Order = v
+	return &c
+}
+
+func (o orderSub) withOrder(v float64) component.Component {
+	c := *o.Subcategory
+	c.Order = v
+	return &c
+}
+
+func (o orderDiff) withOrder(v float64) component.Component {
+	c := *o.Difficulty
+	c.Order = v
+	return &c
+}
+
+func (o orderItem) withOrder(v float64) component.Component {
+	c := *o.Item
+	c.Order = v
+	return &c
+}
+
+// children returns the components of the locale under the partial reference,
+// by ID, with the IDs in the current order
+func (r *Repo) children(parent component.Ref, locale string) (map[string]orderable, []string) {
+	var (
+		list []orderable
+		ids  []string
+		m    = make(map[string]orderable)
+	)
+	switch parent.Level() {
+	case 0:
+		for _, id := range r.Categories(locale) {
+			list = append(list, orderCat{r.Category(id, locale)})
+		}
+	default:
+		cat := r.Category(parent.Category, locale)
+		if cat == nil {
+			return nil, nil
+		}
+		if parent.Level() == 1 {
+			for _, id := range cat.Subcategories() {
+				list = append(list, orderSub{cat.Sub(id)})
+			}
+			break
+		}
+		sub := cat.Sub(parent.Subcategory)
+		if sub == nil {
+			return nil, nil
+		}
+		if parent.Level() == 2 {
+			for _, id := range sub.DifficultyNames() {
+				list = append(list, orderDiff{sub.Difficulty(id)})
+			}
+			break
+		}
+		diff := sub.Difficulty(parent.Difficulty)
+		if diff == nil {
+			return nil, nil
+		}
+		for _, id := range diff.ItemNames() {
+			list = append(list, orderItem{diff.Item(id)})
+		}
+	}
+	for _, v := range list {
+		m[v.id()] = v
+		ids = append(ids, v.id())
+	}
+	return m, ids
+}
+
+// Reorder sorts the children of the partial reference as the IDs, a permutation of
+// the current ones, changing their Order in every locale in a single commit
+func (r *Repo) Reorder(parent component.Ref, ids []string, locale string, u models.User, token string, info Commit) error {
+	if err := r.checkWrite(u); err != nil {
+		return err
+	}
+	changes, err := r.orderChanges(parent, ids, locale, u)
+	if err != nil {
+		return err
+	}
+	if len(changes) == 0 {
+		return nil
+	}
+	path := parent.Prefix()
+	if path == "" {
+		path = "categories"
+	}
+	msg, err := r.message(actionOrder, path, u, info)
+	if err != nil {
+		return err
+	}
+	if err := r.push(changes, msg, u, token); err != nil {
+		return err
+	}
+	go r.Pull()
+	return nil
+}
+
+// orderChanges returns the changes to the metadata of the children with a new Order
+func (r *Repo) orderChanges(parent component.Ref, ids []string, locale string, u models.User) ([]change, error) {
+	r.RLock()
+	head := r.commit
+	r.RUnlock()
+	if head == nil {
+		return nil, ErrNotReady
+	}
+	current, list := r.children(parent, locale)
+	if current == nil && parent.Level() > 0 {
+		return nil, ErrNotFound
+	}
+	if len(ids) != len(list) {
+		return nil, ErrOrder
+	}
+	var (
+		orders = make([]float64, len(ids))
+		seen   = make(map[string]bool)
+	)
+	for i, id := range ids {
+		c, ok := current[id]
+		if !ok || seen[id] {
+			return nil, ErrOrder
+		}
+		seen[id] = true
+		orders[i] = c.order()
+	}
+	values := component.Reorder(orders)
+	var changes []change
+	for _, l := range r.Locale() {
+		cmps, _ := r.children(parent, l)
+		for i, id := range ids {
+			c, ok := cmps[id]
+			if !ok || c.order() == values[i] {
+				continue
+			}
+			if r.leases != nil {
+				if _, err := r.leases.Check(c.Path(), u); err != nil {
+					return nil, err
+				}
+			}
+			f, err := head.File(c.Path())
+			if err != nil {
+				return nil, err
+			}
+			contents, err := r.contents(c.withOrder(values[i]))
+			if err != nil {
+				return nil, err
+			}
+			changes = append(changes, change{Path: c.Path(), SHA: f.Hash.String(), Contents: strPtr(contents)})
+		}
+	}
+	return changes, nil
+}
@@ -0,0 +1,57 @@
+package repo
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/securityfirst/tent/audit"
+	"github.com/securityfirst/tent/logging"
+	"github.com/securityfirst/tent/models"
+)
+
+func TestReorder(t *testing.T) {
+	r, dir := newTestRepo(t, map[string]string{
+		"contents_en/a/.metadata.md": "[Name]: # (A)\n[Order]: # (1)",
+		"contents_en/b/.metadata.md": "[Name]: # (B)\n[Order]: # (2)",
+	})
+	defer os.RemoveAll(dir)
+	l, err := audit.Open(filepath.Join(dir, "audit.log"), logging.IPNone, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+	r.SetAudit(l)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodPut, "/api/repo/order", bytes.NewBufferString(`{"order":["b","a"]}`))
+	c.Set("user", models.User{ID: "github:editor", Login: "editor", Name: "Editor", Email: "editor@tent.org"})
+	c.Set("token", "")
+	c.Set("locale", "en")
+	h := r.Handler()
+	h.Reorder(c)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected %d, got %d: %s", http.StatusOK, w.Code, w.Body)
+	}
+	for name, contents := range map[string]string{
+		"contents_en/a/.metadata.md": "[Name]: # (A)\n[Order]: # (3)",
+		"contents_en/b/.metadata.md": "[Name]: # (B)\n[Order]: # (2)",
+	} {
+		if s, err := headFile(t, dir, name); err != nil || s != contents {
+			t.Errorf("%s: expected %q, got %q: %v", name, contents, s, err)
+		}
+	}
+	list, err := l.Query(audit.Query{Action: "order"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(list) != 1 || list[0].User != "github:editor" || list[0].Login != "editor" || list[0].Path != "contents_en" || list[0].Outcome != audit.OutcomeOK {
+		t.Errorf("unexpected audit entries %+v", list)
+	}
+}
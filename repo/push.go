@@ -0,0 +1,273 @@
+package repo
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/securityfirst/tent/models"
+
+	git "gopkg.in/src-d/go-git.v4"
+	"gopkg.in/src-d/go-git.v4/config"
+	"gopkg.in/src-d/go-git.v4/plumbing"
+	"gopkg.in/src-d/go-git.v4/plumbing/filemode"
+	"gopkg.in/src-d/go-git.v4/plumbing/object"
+	"gopkg.in/src-d/go-git.v4/plumbing/transport"
+	"gopkg.in/src-d/go-git.v4/plumbing/transport/http"
+)
+
+var ErrConflict = errors.New("file changed")
+
+// change is the new contents of a file, nil contents delete it
+type change struct {
+	Path     string
+	Contents *string
+	SHA      string
+	Create   bool
+}
+
+// push commits the changes using go-git, signing it, and pushes it to the branch.
+// Pushes are serialized, the network calls are made without the lock of the repository.
+func (r *Repo) push(changes []change, msg string, u models.User, token string) error {
+	r.pushing.Lock()
+	defer r.pushing.Unlock()
+
+	auth, err := r.auth()
+	if err != nil {
+		return err
+	}
+	err = r.repo.Fetch(&git.FetchOptions{Auth: auth})
+	if err != nil && err != git.NoErrAlreadyUpToDate {
+		return err
+	}
+	local, err := r.prepare(changes, msg, u)
+	if err != nil {
+		return err
+	}
+	if auth, err = r.pushAuth(token); err != nil {
+		return err
+	}
+	return r.repo.Push(&git.PushOptions{
+		RefSpecs: []config.RefSpec{config.RefSpec(fmt.Sprintf("%s:refs/heads/%s", local, r.branch))},
+		Auth:     auth,
+	})
+}
+
+// prepare commits the changes on top of the fetched branch and returns the
+// local reference of the commit
+func (r *Repo) prepare(changes []change, msg string, u models.User) (plumbing.ReferenceName, error) {
+	r.RLock()
+	defer r.RUnlock()
+
+	ref, err := r.repo.Reference(plumbing.ReferenceName("refs/remotes/origin/"+r.branch), false)
+	if err != nil {
+		return "", err
+	}
+	parent, err := r.repo.CommitObject(ref.Hash())
+	if err != nil {
+		return "", err
+	}
+	tree, err := parent.Tree()
+	if err != nil {
+		return "", err
+	}
+	for _, ch := range changes {
+		if err := checkChange(tree, ch); err != nil {
+			return "", err
+		}
+	}
+	root := tree.Hash
+	for _, ch := range changes {
+		var blob plumbing.Hash
+		if ch.Contents != nil {
+			if blob, err = r.storeBlob(*ch.Contents); err != nil {
+				return "", err
+			}
+		}
+		var t *object.Tree
+		if !root.IsZero() {
+			if t, err = r.repo.TreeObject(root); err != nil {
+				return "", err
+			}
+		}
+		if root, err = r.updateTree(t, strings.Split(ch.Path, "/"), blob); err != nil {
+			return "", err
+		}
+	}
+	hash, err := r.storeCommit(root, parent.Hash, msg, u)
+	if err != nil {
+		return "", err
+	}
+	local := plumbing.ReferenceName("refs/heads/tent-" + r.branch)
+	if err := r.repo.Storer.SetReference(plumbing.NewHashReference(local, hash)); err != nil {
+		return "", err
+	}
+	return local, nil
+}
+
+// auth returns the credentials of the repository
+func (r *Repo) auth() (transport.AuthMethod, error) {
+	if r.creds == nil {
+		return nil, nil
+	}
+	return r.creds()
+}
+
+// pushAuth returns the credentials used to push: the ones of the App, the ones of
+// the repository if enabled for pushes or local, otherwise the user token
+func (r *Repo) pushAuth(token string) (transport.AuthMethod, error) {
+	if r.app != nil || r.pushCreds || r.owner == "" {
+		return r.auth()
+	}
+	if token == "" {
+		return nil, ErrCredentials
+	}
+	return &http.BasicAuth{Username: "tent", Password: token}, nil
+}
+
+// checkChange verifies that the file is in the state expected by the change
+func checkChange(tree *object.Tree, ch change) error {
+	f, err := tree.File(ch.Path)
+	switch {
+	case err == object.ErrFileNotFound:
+		if !ch.Create {
+			return ErrFileNotFound
+		}
+		return nil
+	case err != nil:
+		return err
+	case ch.Create:
+		return ErrExists
+	case f.Hash.String() != ch.SHA:
+		return ErrConflict
+	}
+	return nil
+}
+
+func (r *Repo) storeBlob(contents string) (plumbing.Hash, error) {
+	obj := r.repo.Storer.NewEncodedObject()
+	obj.SetType(plumbing.BlobObject)
+	w, err := obj.Writer()
+	if err != nil {
+		return plumbing.ZeroHash, err
+	}
+	if _, err := w.Write([]byte(contents)); err != nil {
+		return plumbing.ZeroHash, err
+	}
+	if err := w.Close(); err != nil {
+		return plumbing.ZeroHash, err
+	}
+	return r.repo.Storer.SetEncodedObject(obj)
+}
+
+// updateTree sets the blob at path, creating the trees needed, a zero blob removes
+// the entry, it returns the hash of the new tree or a zero one if it's empty
+func (r *Repo) updateTree(t *object.Tree, parts []string, blob plumbing.Hash) (plumbing.Hash, error) {
+	var entries []object.TreeEntry
+	if t != nil {
+		entries = append(entries, t.Entries...)
+	}
+	idx := -1
+	for i := range entries {
+		if entries[i].Name == parts[0] {
+			idx = i
+			break
+		}
+	}
+	entry := object.TreeEntry{Name: parts[0], Mode: filemode.Regular, Hash: blob}
+	if len(parts) > 1 {
+		var sub *object.Tree
+		if idx >= 0 {
+			var err error
+			if sub, err = r.repo.TreeObject(entries[idx].Hash); err != nil {
+				return plumbing.ZeroHash, err
+			}
+		}
+		h, err := r.updateTree(sub, parts[1:], blob)
+		if err != nil {
+			return plumbing.ZeroHash, err
+		}
+		entry.Mode, entry.Hash = filemode.Dir, h
+	}
+	switch {
+	case entry.Hash.IsZero() && idx >= 0:
+		entries = append(entries[:idx], entries[idx+1:]...)
+	case entry.Hash.IsZero():
+	case idx >= 0:
+		entries[idx] = entry
+	default:
+		entries = append(entries, entry)
+	}
+	if len(entries) == 0 {
+		return plumbing.ZeroHash, nil
+	}
+	sort.Sort(treeSorter(entries))
+	obj := r.repo.Storer.NewEncodedObject()
+	if err := (&object.Tree{Entries: entries}).Encode(obj); err != nil {
+		return plumbing.ZeroHash, err
+	}
+	return r.repo.Storer.SetEncodedObject(obj)
+}
+
+// storeCommit creates the commit, signed if a signer is configured
+func (r *Repo) storeCommit(tree, parent plumbing.Hash, msg string, u models.User) (plumbing.Hash, error) {
+	now := time.Now()
+	author := object.Signature{Name: u.Name, Email: u.Email, When: now}
+	committer := author
+	if r.committer.Email != "" {
+		committer = object.Signature{Name: r.committer.Name, Email: r.committer.Email, When: now}
+	}
+	commit := object.Commit{
+		Author:       author,
+		Committer:    committer,
+		Message:      msg,
+		TreeHash:     tree,
+		ParentHashes: []plumbing.Hash{parent},
+	}
+	if r.signer != nil {
+		obj := r.repo.Storer.NewEncodedObject()
+		if err := commit.Encode(obj); err != nil {
+			return plumbing.ZeroHash, err
+		}
+		payload, err := readObject(obj)
+		if err != nil {
+			return plumbing.ZeroHash, err
+		}
+		if commit.PGPSignature, err = r.signer.Sign(payload); err != nil {
+			return plumbing.ZeroHash, err
+		}
+	}
+	obj := r.repo.Storer.NewEncodedObject()
+	if err := commit.Encode(obj); err != nil {
+		return plumbing.ZeroHash, err
+	}
+	return r.repo.Storer.SetEncodedObject(obj)
+}
+
+func readObject(obj plumbing.EncodedObject) ([]byte, error) {
+	rd, err := obj.Reader()
+	if err != nil {
+		return nil, err
+	}
+	defer rd.Close()
+	return ioutil.ReadAll(rd)
+}
+
+// treeSorter sorts entries as git does, directories are compared with a trailing slash
+type treeSorter []object.TreeEntry
+
+func (s treeSorter) Len() int      { return len(s) }
+func (s treeSorter) Swap(i, j int) { s[i], s[j] = s[j], s[i] }
+func (s treeSorter) Less(i, j int) bool {
+	return s.name(i) < s.name(j)
+}
+
+func (s treeSorter) name(i int) string {
+	if s[i].Mode == filemode.Dir {
+		return s[i].Name + "/"
+	}
+	return s[i].Name
+}
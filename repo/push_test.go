@@ -0,0 +1,173 @@
+package repo
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/securityfirst/tent/models"
+
+	git "gopkg.in/src-d/go-git.v4"
+	"gopkg.in/src-d/go-git.v4/plumbing"
+	"gopkg.in/src-d/go-git.v4/plumbing/filemode"
+	"gopkg.in/src-d/go-git.v4/plumbing/object"
+)
+
+// newTestRepo creates a bare repository with the files in a temporary
+// directory, and returns it cloned and parsed, with its directory
+func newTestRepo(t *testing.T, files map[string]string) (*Repo, string) {
+	dir, err := ioutil.TempDir("", "tent")
+	if err != nil {
+		t.Fatal(err)
+	}
+	src := filepath.Join(dir, "src")
+	g, err := git.PlainInit(src, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w, err := g.Worktree()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name, contents := range files {
+		p := filepath.Join(src, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(p, []byte(contents), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := w.Add(name); err != nil {
+			t.Fatal(err)
+		}
+	}
+	sig := &object.Signature{Name: "Test", Email: "test@tent.org", When: time.Now()}
+	if _, err := w.Commit("Initial", &git.CommitOptions{Author: sig}); err != nil {
+		t.Fatal(err)
+	}
+	bare := filepath.Join(dir, "bare")
+	if _, err := git.PlainClone(bare, true, &git.CloneOptions{URL: src}); err != nil {
+		t.Fatal(err)
+	}
+	r, err := Local(bare, "master")
+	if err != nil {
+		t.Fatal(err)
+	}
+	r.Pull()
+	return r, dir
+}
+
+// headFile returns the contents of the file in the branch of the bare repository
+func headFile(t *testing.T, dir, name string) (string, error) {
+	g, err := git.PlainOpen(filepath.Join(dir, "bare"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ref, err := g.Reference(plumbing.ReferenceName("refs/heads/master"), true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c, err := g.CommitObject(ref.Hash())
+	if err != nil {
+		t.Fatal(err)
+	}
+	f, err := c.File(name)
+	if err != nil {
+		return "", err
+	}
+	return f.Contents()
+}
+
+func TestTreeSorter(t *testing.T) {
+	entries := []object.TreeEntry{
+		{Name: "a", Mode: filemode.Dir},
+		{Name: "a.md", Mode: filemode.Regular},
+		{Name: "a-b", Mode: filemode.Regular},
+		{Name: "B", Mode: filemode.Regular},
+	}
+	sort.Sort(treeSorter(entries))
+	var names []string
+	for _, e := range entries {
+		names = append(names, e.Name)
+	}
+	expected := []string{"B", "a-b", "a.md", "a"}
+	for i := range expected {
+		if names[i] != expected[i] {
+			t.Fatalf("expected %v, got %v", expected, names)
+		}
+	}
+}
+
+func TestUpdateTree(t *testing.T) {
+	r, dir := newTestRepo(t, map[string]string{"a/b.md": "b", "c.md": "c"})
+	defer os.RemoveAll(dir)
+	tree, err := r.commit.Tree()
+	if err != nil {
+		t.Fatal(err)
+	}
+	blob, err := r.storeBlob("d")
+	if err != nil {
+		t.Fatal(err)
+	}
+	h, err := r.updateTree(tree, []string{"a", "x", "d.md"}, blob)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tree, err = r.repo.TreeObject(h); err != nil {
+		t.Fatal(err)
+	}
+	for name, contents := range map[string]string{"a/b.md": "b", "a/x/d.md": "d", "c.md": "c"} {
+		f, err := tree.File(name)
+		if err != nil {
+			t.Fatalf("%s: %s", name, err)
+		}
+		if s, _ := f.Contents(); s != contents {
+			t.Errorf("%s: expected %q, got %q", name, contents, s)
+		}
+	}
+	// removing the only file removes its directories
+	if h, err = r.updateTree(tree, []string{"a", "x", "d.md"}, plumbing.ZeroHash); err != nil {
+		t.Fatal(err)
+	}
+	if tree, err = r.repo.TreeObject(h); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := tree.Tree("a/x"); err == nil {
+		t.Error("expected empty directory to be removed")
+	}
+	if h, err = r.updateTree(nil, []string{"c.md"}, plumbing.ZeroHash); err != nil || !h.IsZero() {
+		t.Errorf("expected empty tree, got %s: %v", h, err)
+	}
+}
+
+func TestPush(t *testing.T) {
+	r, dir := newTestRepo(t, map[string]string{"a.md": "a"})
+	defer os.RemoveAll(dir)
+	u := models.User{Name: "Editor", Email: "editor@tent.org"}
+	contents := "b"
+	if err := r.push([]change{{Path: "b/b.md", Contents: &contents, Create: true}}, "Add b", u, ""); err != nil {
+		t.Fatal(err)
+	}
+	if s, err := headFile(t, dir, "b/b.md"); err != nil || s != contents {
+		t.Errorf("unexpected contents %q: %v", s, err)
+	}
+	sha := blobHash("a")
+	for _, ch := range []change{
+		{Path: "b/b.md", Contents: &contents, Create: true},
+		{Path: "a.md", Contents: &contents, SHA: blobHash("wrong")},
+		{Path: "c.md", SHA: sha},
+	} {
+		if err := r.push([]change{ch}, "Change", u, ""); err == nil {
+			t.Errorf("%s: expected error", ch.Path)
+		}
+	}
+	if err := r.push([]change{{Path: "a.md", SHA: sha}}, "Delete a", u, ""); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := headFile(t, dir, "a.md"); err != object.ErrFileNotFound {
+		t.Errorf("expected deleted file, got %v", err)
+	}
+}
@@ -5,24 +5,32 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
-	"log"
 	"os"
 	"path"
+	"strings"
 	"sync"
+	"text/template"
 
+	"github.com/securityfirst/tent/audit"
+	"github.com/securityfirst/tent/auth"
 	"github.com/securityfirst/tent/component"
+	"github.com/securityfirst/tent/logging"
 	"github.com/securityfirst/tent/models"
 
 	git "gopkg.in/src-d/go-git.v4"
 	"gopkg.in/src-d/go-git.v4/plumbing"
 	"gopkg.in/src-d/go-git.v4/plumbing/object"
-	"gopkg.in/src-d/go-git.v4/storage/memory"
+	"gopkg.in/src-d/go-git.v4/plumbing/transport"
+	gitssh "gopkg.in/src-d/go-git.v4/plumbing/transport/ssh"
 
 	"github.com/google/go-github/github"
 	"golang.org/x/oauth2"
 )
 
-var logger = log.New(os.Stdout, "[repo]", log.Ltime|log.Lshortfile)
+var logger = logging.New(os.Stdout, "repo", logging.Config{})
+
+// SetLogger sets the logger of the package
+func SetLogger(l *logging.Logger) { logger = l }
 
 var (
 	ErrNotReady     = errors.New("Repository not ready")
@@ -33,17 +41,21 @@ const (
 	actionCreate = iota
 	actionUpdate
 	actionDelete
+	actionMove
+	actionOrder
 )
 
 var commitMsg = map[int]string{
 	actionCreate: "Create",
 	actionUpdate: "Update",
 	actionDelete: "Delete",
+	actionMove:   "Move",
+	actionOrder:  "Order",
 }
 
 func Local(dir, branch string) (*Repo, error) {
-	logger.Printf("Using %q", dir)
-	r, err := git.Clone(memory.NewStorage(), nil, &git.CloneOptions{URL: fmt.Sprintf("file://%s", dir)})
+	logger.Infof("Using %q", dir)
+	r, err := git.Clone(newStorage(), nil, &git.CloneOptions{URL: fmt.Sprintf("file://%s", dir)})
 	if err != nil {
 		return nil, err
 	}
@@ -53,41 +65,124 @@ func Local(dir, branch string) (*Repo, error) {
 	return &Repo{repo: r, name: path.Base(dir), branch: branch}, nil
 }
 
-func New(owner, name, branch string) (*Repo, error) {
+// New clones the Github repository, using the credentials if not nil
+func New(owner, name, branch string, creds Credentials) (*Repo, error) {
 	address := repoAddress(owner, name)
-	logger.Printf("Using %q", address)
-	r, err := git.Clone(memory.NewStorage(), nil, &git.CloneOptions{URL: address})
+	var auth transport.AuthMethod
+	if creds != nil {
+		var err error
+		if auth, err = creds(); err != nil {
+			return nil, err
+		}
+	}
+	if _, ok := auth.(*gitssh.PublicKeys); ok {
+		address = sshAddress(owner, name)
+	}
+	logger.Infof("Using %q", address)
+	r, err := git.Clone(newStorage(), nil, &git.CloneOptions{URL: address, Auth: auth})
 	if err != nil {
 		return nil, err
 	}
 	if branch == "" {
 		branch = "master"
 	}
-	return &Repo{repo: r, name: name, owner: owner, branch: branch}, nil
+	return &Repo{repo: r, name: name, owner: owner, branch: branch, creds: creds}, nil
 }
 
 type Repo struct {
 	sync.RWMutex
+	pushing    sync.Mutex
 	owner      string
 	name       string
 	branch     string
 	conf       *oauth2.Config
+	creds      Credentials
+	pushCreds  bool
+	app        *App
+	audit      *audit.Log
+	leases     *Leases
+	messages   map[int]*template.Template
+	signer     signer
+	committer  models.User
 	repo       *git.Repository
 	commit     *object.Commit
 	categories map[string][]*component.Category
 	assets     []*component.Asset
 	forms      []*component.Form
+	tags       component.TagIndex
+	profile    *component.Profile
+	nodes      map[string][]*component.Node
+	types      map[string]map[string][]*component.Node
+	glossary   map[string][]component.Term
+	linkTerms  bool
+	basePath   string
+	format     string
+	refs       component.RefIndex
+	dangling   []component.RefError
+	orphans    []component.OrphanError
 }
 
 func (r *Repo) SetConf(c *oauth2.Config) { r.conf = c }
 
-func (r *Repo) Tree(locale string, html bool) interface{} {
+// SetPushCredentials uses the credentials of the repository for pushes too
+func (r *Repo) SetPushCredentials(v bool) { r.pushCreds = v }
+
+// SetApp sets the Github App used for all the writes instead of the user token
+func (r *Repo) SetApp(a *App) { r.app = a }
+
+// SetAudit sets the log used to record write operations
+func (r *Repo) SetAudit(l *audit.Log) { r.audit = l }
+
+// SetLeases sets the leases used to lock components while editing
+func (r *Repo) SetLeases(l *Leases) { r.leases = l }
+
+// SetProfile sets the hierarchy of the nodes, the default one is used otherwise
+func (r *Repo) SetProfile(p component.Profile) error {
+	if err := p.Validate(); err != nil {
+		return err
+	}
+	r.profile = &p
+	return nil
+}
+
+// SetMetaFormat sets the syntax used to write the metadata, rows if empty
+func (r *Repo) SetMetaFormat(format string) error {
+	format, err := component.CheckFormat(format)
+	if err != nil {
+		return err
+	}
+	r.format = format
+	return nil
+}
+
+// contents returns the contents of the component, with the metadata in the syntax of the repository
+func (r *Repo) contents(c component.Component) (string, error) {
+	p := component.Parser{Format: r.format}
+	return p.Contents(c)
+}
+
+// SetGlossaryLinks enables the links to the glossary terms in the HTML of the items
+func (r *Repo) SetGlossaryLinks(v bool) { r.linkTerms = v }
+
+// SetBasePath sets the path where the API is served, used by the links in the HTML of the items
+func (r *Repo) SetBasePath(p string) { r.basePath = strings.TrimSuffix(p, "/") }
+
+// Profile returns the hierarchy of the nodes
+func (r *Repo) Profile() *component.Profile {
+	if r.profile == nil {
+		return &component.DefaultProfile
+	}
+	return r.profile
+}
+
+// Tree returns all the contents of the locale, all includes the ones not published
+func (r *Repo) Tree(locale string, html, all bool) interface{} {
 	r.RLock()
 	defer r.RUnlock()
 
 	var cats = make([]interface{}, 0, len(r.categories))
 	for _, i := range r.Categories(locale) {
-		cats = append(cats, r.Category(i, locale).Tree(html))
+		cats = append(cats, r.Category(i, locale).Tree(html, all))
 	}
 
 	var ass = make([]string, len(r.assets))
@@ -97,7 +192,7 @@ func (r *Repo) Tree(locale string, html bool) interface{} {
 
 	var forms = make([]*component.Form, 0)
 	for i := range r.forms {
-		if r.forms[i].Locale != locale {
+		if r.forms[i].Locale != locale || !all && !component.Published(r.forms[i]) {
 			continue
 		}
 		forms = append(forms, r.forms[i])
@@ -111,6 +206,9 @@ func (r *Repo) Tree(locale string, html bool) interface{} {
 }
 
 func (r *Repo) client(token string) *github.Client {
+	if r.app != nil {
+		return r.app.client()
+	}
 	return github.NewClient(r.conf.Client(oauth2.NoContext, &oauth2.Token{AccessToken: token}))
 }
 
@@ -147,6 +245,11 @@ func (r *Repo) All(locale string) []component.Component {
 		}
 		list = append(list, form)
 	}
+	for _, t := range component.Types() {
+		for _, n := range component.Flatten(r.types[t.Name][locale]) {
+			list = append(list, n)
+		}
+	}
 	return list
 }
 
@@ -173,43 +276,81 @@ func (r *Repo) Pull() {
 	r.Lock()
 	defer r.Unlock()
 
-	err := r.repo.Fetch(&git.FetchOptions{})
+	auth, err := r.auth()
+	if err != nil {
+		logger.Errorf("Pull failed: %s", err)
+		return
+	}
+	err = r.repo.Fetch(&git.FetchOptions{Auth: auth})
 	if err != nil && err != git.NoErrAlreadyUpToDate {
-		logger.Println("Pull failed:", err)
+		logger.Errorf("Pull failed: %s", err)
 		return
 	}
 	branch := plumbing.ReferenceName("refs/remotes/origin/" + r.branch)
 	hash, err := r.repo.Reference(branch, false)
 	if err != nil {
-		logger.Printf("Reference %q failed:", branch, err)
+		logger.Errorf("Reference %q failed: %s", branch, err)
 		return
 	}
 	if r.commit != nil && r.commit.Hash == hash.Hash() {
 		return
 	}
 	if r.commit != nil {
-		logger.Println("Changing commit from", r.commit.Hash, "to", hash)
+		logger.Infof("Changing commit from %s to %s", r.commit.Hash, hash)
 	} else {
-		logger.Println("Checkout with", hash)
+		logger.Infof("Checkout with %s", hash)
 	}
 	r.commit, err = r.repo.CommitObject(hash.Hash())
 	if err != nil {
-		logger.Println("Commit failed:", err)
+		logger.Errorf("Commit failed: %s", err)
 		return
 	}
-	var parser component.Parser
+	parser := component.Parser{Format: r.format}
 	tree, err := r.commit.Tree()
 	if err != nil {
-		logger.Println("Tree failed:", err)
+		logger.Errorf("Tree failed: %s", err)
 		return
 	}
+	if p := r.Profile(); p.Root != component.DefaultProfile.Root {
+		parser.Exclude(p)
+	}
 	if err := parser.Parse(tree); err != nil {
-		logger.Println("Parsing failed:", err)
+		logger.Errorf("Parsing failed: %s", err)
 		return
 	}
 	r.categories = parser.Categories()
 	r.assets = parser.Assets()
 	r.forms = parser.Forms()
+	r.tags = parser.Tags()
+	r.refs, r.dangling = parser.Refs(), parser.Dangling()
+	var orphans []component.OrphanError
+	if r.nodes, orphans, err = r.Profile().Parse(tree); err != nil {
+		logger.Errorf("Parsing %s nodes failed: %s", r.Profile().Name, err)
+	}
+	r.orphans = orphans
+	r.types = make(map[string]map[string][]*component.Node)
+	for _, t := range component.Types() {
+		if r.types[t.Name], orphans, err = t.Parse(tree); err != nil {
+			logger.Errorf("Parsing %s failed: %s", t.Name, err)
+		}
+		r.orphans = append(r.orphans, orphans...)
+	}
+	for l, cats := range r.categories {
+		component.LinkRefs(cats, r.basePath, l)
+	}
+	r.glossary = make(map[string][]component.Term)
+	for l, nodes := range r.types[component.GlossaryType.Name] {
+		r.glossary[l] = component.Glossary(nodes)
+		if r.linkTerms {
+			component.LinkGlossary(r.categories[l], r.glossary[l], r.basePath, l)
+		}
+	}
+	for _, e := range r.dangling {
+		logger.Warnf("Reference: %s", e)
+	}
+	for _, e := range r.orphans {
+		logger.Warnf("Node: %s", e)
+	}
 }
 
 func (r *Repo) file(c component.Component) (*object.File, error) {
@@ -265,6 +406,125 @@ func (r *Repo) Form(id string, locale string) *component.Form {
 	return nil
 }
 
+// Nodes returns the nodes of the first level of the locale
+func (r *Repo) Nodes(locale string) []*component.Node {
+	r.RLock()
+	defer r.RUnlock()
+	return r.nodes[locale]
+}
+
+// Node returns the node of the locale with the IDs, from the first level
+func (r *Repo) Node(locale string, ids ...string) *component.Node {
+	r.RLock()
+	defer r.RUnlock()
+	return component.Find(r.nodes[locale], ids...)
+}
+
+// Glossary returns the terms of the locale
+func (r *Repo) Glossary(locale string) []component.Term {
+	r.RLock()
+	defer r.RUnlock()
+	return r.glossary[locale]
+}
+
+// Term returns the glossary term of the locale with the ID
+func (r *Repo) Term(id, locale string) *component.Term {
+	r.RLock()
+	defer r.RUnlock()
+	for i, t := range r.glossary[locale] {
+		if t.ID == id {
+			return &r.glossary[locale][i]
+		}
+	}
+	return nil
+}
+
+// TypeNodes returns the nodes of the first level of the custom type in the locale
+func (r *Repo) TypeNodes(typ, locale string) []*component.Node {
+	r.RLock()
+	defer r.RUnlock()
+	return r.types[typ][locale]
+}
+
+// TypeNode returns the node of the custom type in the locale with the IDs
+func (r *Repo) TypeNode(typ, locale string, ids ...string) *component.Node {
+	r.RLock()
+	defer r.RUnlock()
+	return component.Find(r.types[typ][locale], ids...)
+}
+
+// ReferencedBy returns the references to the items that link the path, all
+// includes the ones that are not published
+func (r *Repo) ReferencedBy(path string, all bool) []component.Ref {
+	r.RLock()
+	defer r.RUnlock()
+	var refs = make([]component.Ref, 0, len(r.refs[path]))
+	for _, i := range r.refs[path] {
+		if all || component.Published(i) {
+			refs = append(refs, i.Ref())
+		}
+	}
+	return refs
+}
+
+// Dangling returns the references to missing items of the locale, all the locales if empty
+func (r *Repo) Dangling(locale string) []component.RefError {
+	r.RLock()
+	defer r.RUnlock()
+	var list = make([]component.RefError, 0, len(r.dangling))
+	for _, e := range r.dangling {
+		if locale == "" || strings.HasPrefix(e.Path, "contents_"+locale+"/") {
+			list = append(list, e)
+		}
+	}
+	return list
+}
+
+// Orphans returns the nodes of the locale skipped for a missing parent, all the locales if empty
+func (r *Repo) Orphans(locale string) []component.OrphanError {
+	r.RLock()
+	defer r.RUnlock()
+	var list = make([]component.OrphanError, 0, len(r.orphans))
+	for _, e := range r.orphans {
+		if locale == "" || e.Locale == locale {
+			list = append(list, e)
+		}
+	}
+	return list
+}
+
+// Tags returns the number of items for each tag of the locale, all includes the
+// items that are not published
+func (r *Repo) Tags(locale string, all bool) map[string]int {
+	r.RLock()
+	defer r.RUnlock()
+	var tags = make(map[string]int)
+	for _, tag := range r.tags.Tags(locale) {
+		if n := len(r.tagged(locale, tag, all)); n != 0 {
+			tags[tag] = n
+		}
+	}
+	return tags
+}
+
+// Tagged returns the items of the locale with the tag, all includes the ones
+// that are not published
+func (r *Repo) Tagged(locale, tag string, all bool) []component.Tagged {
+	r.RLock()
+	defer r.RUnlock()
+	return r.tagged(locale, tag, all)
+}
+
+func (r *Repo) tagged(locale, tag string, all bool) []component.Tagged {
+	var items = make([]component.Tagged, 0)
+	for _, t := range r.tags.Items(locale, tag) {
+		if all || component.Published(t.Item) {
+			items = append(items, t)
+		}
+	}
+	return items
+}
+
 func (r *Repo) Category(cat, locale string) *component.Category {
 	r.RLock()
 	defer r.RUnlock()
@@ -295,31 +555,70 @@ func (r *Repo) ComponentHash(c component.Component) (string, error) {
 	return f.Hash.String(), nil
 }
 
-func (r *Repo) Create(c component.Component, u models.User, token string) error {
-	return r.request(c, actionCreate, u, token)
+func (r *Repo) Create(c component.Component, u models.User, token string, commit Commit) error {
+	return r.request(c, actionCreate, u, token, commit)
 }
 
-func (r *Repo) Delete(c component.Component, u models.User, token string) error {
-	return r.request(c, actionDelete, u, token)
+func (r *Repo) Delete(c component.Component, u models.User, token string, commit Commit) error {
+	return r.request(c, actionDelete, u, token, commit)
 }
 
-func (r *Repo) Update(c component.Component, u models.User, token string) error {
-	return r.request(c, actionUpdate, u, token)
+func (r *Repo) Update(c component.Component, u models.User, token string, commit Commit) error {
+	return r.request(c, actionUpdate, u, token, commit)
 }
 
-func (r *Repo) request(c component.Component, action int, u models.User, token string) (err error) {
+// checkWrite returns ErrPermission if the App commits for a Github user that cannot
+// write the repository, other users are authorized by their provider
+func (r *Repo) checkWrite(u models.User) error {
+	if r.app == nil || !strings.HasPrefix(u.ID, auth.ProviderGitHub+":") {
+		return nil
+	}
+	ok, err := r.app.CanWrite(u.Login)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrPermission
+	}
+	return nil
+}
+
+func (r *Repo) request(c component.Component, action int, u models.User, token string, info Commit) (err error) {
+	if err := r.checkWrite(u); err != nil {
+		return err
+	}
 	file := c.Path()
-	msg := fmt.Sprintf("%s %s", commitMsg[action], file)
+	msg, err := r.message(action, file, u, info)
+	if err != nil {
+		return err
+	}
+	var contents string
+	if action != actionDelete {
+		if contents, err = r.contents(c); err != nil {
+			return err
+		}
+	}
+	if r.signer != nil {
+		ch := change{Path: file, SHA: c.SHA(), Create: action == actionCreate}
+		if action != actionDelete {
+			ch.Contents = strPtr(contents)
+		}
+		if err := r.push([]change{ch}, msg, u, token); err != nil {
+			return err
+		}
+		go r.Pull()
+		return nil
+	}
 	commit := &github.RepositoryContentFileOptions{
 		Message: &msg, Author: u.AsAuthor(),
 	}
 	switch action {
 	case actionCreate:
-		commit.Content = []byte(c.Contents())
+		commit.Content = []byte(contents)
 		_, _, err = r.client(token).Repositories.CreateFile(context.Background(), r.owner, r.name, file, commit)
 	case actionUpdate:
 		commit.SHA = strPtr(c.SHA())
-		commit.Content = []byte(c.Contents())
+		commit.Content = []byte(contents)
 		_, _, err = r.client(token).Repositories.UpdateFile(context.Background(), r.owner, r.name, file, commit)
 	case actionDelete:
 		commit.SHA = strPtr(c.SHA())
@@ -333,6 +632,11 @@ func (r *Repo) request(c component.Component, action int, u models.User, token s
 
 func strPtr(s string) *string { return &s }
 
+// blobHash returns the git hash of the contents
+func blobHash(contents string) string {
+	return plumbing.ComputeHash(plumbing.BlobObject, []byte(contents)).String()
+}
+
 func repoAddress(owner, name string) string {
 	return fmt.Sprintf("https://github.com/%s/%s", owner, name)
 }
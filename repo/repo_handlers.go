@@ -4,16 +4,23 @@ import (
 	"bytes"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"io"
 	"log"
 	"net/http"
+	"net/mail"
 	"path"
 	"path/filepath"
 	"sort"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/go-github/github"
 
+	"github.com/securityfirst/tent/audit"
+	"github.com/securityfirst/tent/auth"
 	"github.com/securityfirst/tent/component"
 	"github.com/securityfirst/tent/models"
 	"gopkg.in/src-d/go-git.v4/plumbing/object"
@@ -24,6 +31,12 @@ var (
 	ErrNotFound    = errors.New("not found")
 	ErrHasChildren = errors.New("element has children")
 	ErrLanguage    = errors.New("invalid language")
+	ErrPermission  = errors.New("permission denied")
+)
+
+const (
+	headerMessage  = "X-Tent-Commit-Message"
+	headerCoAuthor = "X-Tent-Co-Author"
 )
 
 type RepoHandler struct {
@@ -35,11 +48,17 @@ func (r *RepoHandler) err(c *gin.Context, status int, err error) {
 		status = re.Response.StatusCode
 		err = errors.New(re.Message)
 	}
+	if err == ErrPermission {
+		status = http.StatusForbidden
+	}
 	c.JSON(status, gin.H{"error": err.Error()})
 	c.Abort()
 }
 
 func (r *RepoHandler) cmp(c *gin.Context) component.Component {
+	if node, ok := c.Get("node"); ok {
+		return node.(*component.Node)
+	}
 	asset, ok := c.Get("asset")
 	if ok {
 		return asset.(*component.Asset)
@@ -105,12 +124,22 @@ func (r *RepoHandler) checks(c *gin.Context) *component.Checklist {
 	return c.MustGet("checks").(*component.Checklist)
 }
 
+// preview tells if the request can see the components not published
+func (r *RepoHandler) preview(c *gin.Context) bool {
+	return c.GetBool("preview")
+}
+
 func (r *RepoHandler) locale(c *gin.Context) string {
 	return c.MustGet("locale").(string)
 }
 
+// ParseLocale sets the locale of the request from the locale query, used by the
+// glossary links, or the X-Tent-Language header, English by default
 func (r *RepoHandler) ParseLocale(c *gin.Context) {
-	s := c.Request.Header.Get("X-Tent-Language")
+	s := c.Query("locale")
+	if s == "" {
+		s = c.Request.Header.Get("X-Tent-Language")
+	}
 	if s == "" {
 		s = "en"
 	}
@@ -258,6 +287,10 @@ func (r *RepoHandler) ParseItem(c *gin.Context) {
 		r.err(c, http.StatusBadRequest, err)
 		return
 	}
+	if err := component.CheckSchedule(&item); err != nil {
+		r.err(c, http.StatusBadRequest, err)
+		return
+	}
 	item.SetParent(r.diff(c))
 	item.ID = c.Param("item")
 	c.Set("item", &item)
@@ -317,9 +350,36 @@ func (r *RepoHandler) ParseForm(c *gin.Context) {
 		return
 	}
 	form.ID, form.Locale = c.Param("form"), r.locale(c)
+	if c.Request.Method != http.MethodDelete {
+		if err := form.Validate(); err != nil {
+			r.err(c, http.StatusBadRequest, err)
+			return
+		}
+		if err := component.CheckSchedule(&form); err != nil {
+			r.err(c, http.StatusBadRequest, err)
+			return
+		}
+	}
 	c.Set("form", &form)
 }
 
+// FormSchema returns the JSON Schema of the submissions of the form
+func (r *RepoHandler) FormSchema(c *gin.Context) {
+	writeJSON(c, http.StatusOK, r.form(c).Schema())
+}
+
+// FormValidate checks the submission of the form, with the values by input name,
+// and returns the errors by input without storing it
+func (r *RepoHandler) FormValidate(c *gin.Context) {
+	var submission map[string]interface{}
+	if err := c.BindJSON(&submission); err != nil {
+		r.err(c, http.StatusBadRequest, err)
+		return
+	}
+	errs := r.form(c).Check(submission)
+	writeJSON(c, http.StatusOK, gin.H{"valid": len(errs) == 0, "errors": errs})
+}
+
 func (r *RepoHandler) Info(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{
 		"user": r.user(c),
@@ -343,7 +403,7 @@ func (r *RepoHandler) ShowChecks(c *gin.Context) {
 		return
 	}
 	cmp.(*component.Checklist).Hash = hash
-	c.JSON(http.StatusOK, cmp)
+	c.JSON(http.StatusOK, r.withLease(c, cmp, cmp))
 }
 
 func (r *RepoHandler) UpdateChecks(c *gin.Context) {
@@ -379,13 +439,16 @@ func (r *RepoHandler) Show(c *gin.Context) {
 		v.Hash = hash
 		out = &v
 	case *component.Difficulty:
+		if !r.preview(c) {
+			t = t.Published()
+		}
 		v := *t
 		v.Hash = hash
 		out = &v
 	case *component.Item:
 		v := *t
 		v.Hash = hash
-		out = &v
+		out = withFields(&v, gin.H{"referenced_by": r.repo.ReferencedBy(t.Path(), r.preview(c))})
 	case *component.Checklist:
 		v := *t
 		v.Hash = hash
@@ -394,12 +457,343 @@ func (r *RepoHandler) Show(c *gin.Context) {
 		v := *t
 		v.Hash = hash
 		out = &v
+	case *component.Node:
+		if !r.preview(c) {
+			t = t.Published()
+		}
+		v := *t
+		v.Hash = hash
+		out = &v
+	}
+	c.JSON(http.StatusOK, r.withLease(c, cmp, out))
+}
+
+// withLease adds the lease of the component to the output, if any, only for
+// logged in users as it names the editor
+func (r *RepoHandler) withLease(c *gin.Context, cmp component.Component, out interface{}) interface{} {
+	if _, ok := c.Get("user"); !ok || r.repo.leases == nil {
+		return out
+	}
+	l := r.repo.leases.Get(cmp.Path())
+	if l == nil {
+		return out
+	}
+	return withFields(out, gin.H{"lease": l})
+}
+
+// withFields adds the fields to the JSON object of the output
+func withFields(out interface{}, fields gin.H) interface{} {
+	b, err := json.Marshal(out)
+	if err != nil {
+		return out
+	}
+	var m map[string]interface{}
+	if err := json.Unmarshal(b, &m); err != nil {
+		return out
+	}
+	for k, v := range fields {
+		m[k] = v
+	}
+	return m
+}
+
+// locked responds with the lease that blocks the request
+func (r *RepoHandler) locked(c *gin.Context, l *Lease) {
+	c.JSON(http.StatusLocked, gin.H{"error": ErrLocked.Error(), "lease": l})
+	c.Abort()
+}
+
+// IsUnlocked blocks the request if the component is leased by another user
+func (r *RepoHandler) IsUnlocked(c *gin.Context) {
+	if r.repo.leases == nil {
+		return
+	}
+	if l, err := r.repo.leases.Check(r.cmp(c).Path(), r.user(c)); err == ErrLocked {
+		r.locked(c, l)
+	}
+}
+
+// Lock acquires or renews the lease of the component, the duration is optional
+func (r *RepoHandler) Lock(c *gin.Context) {
+	if r.repo.leases == nil {
+		r.err(c, http.StatusNotFound, ErrNotFound)
+		return
+	}
+	var d time.Duration
+	if v := c.Query("duration"); v != "" {
+		var err error
+		if d, err = time.ParseDuration(v); err != nil {
+			r.err(c, http.StatusBadRequest, err)
+			return
+		}
+	}
+	l, err := r.repo.leases.Acquire(r.cmp(c).Path(), r.user(c), d)
+	switch err {
+	case nil:
+		c.JSON(http.StatusOK, l)
+	case ErrLocked:
+		r.locked(c, l)
+	default:
+		r.err(c, http.StatusInternalServerError, err)
+	}
+}
+
+// Unlock releases the lease of the component
+func (r *RepoHandler) Unlock(c *gin.Context) {
+	if r.repo.leases == nil {
+		r.err(c, http.StatusNotFound, ErrNotFound)
+		return
+	}
+	path := r.cmp(c).Path()
+	switch err := r.repo.leases.Release(path, r.user(c)); err {
+	case nil:
+		c.Writer.WriteHeader(http.StatusNoContent)
+	case ErrLease:
+		r.err(c, http.StatusNotFound, err)
+	case ErrLocked:
+		r.locked(c, r.repo.leases.Get(path))
+	default:
+		r.err(c, http.StatusInternalServerError, err)
+	}
+}
+
+// IsVisible hides the components not published to the public
+func (r *RepoHandler) IsVisible(c *gin.Context) {
+	if !r.visible(c, r.cmp(c)) {
+		r.err(c, http.StatusNotFound, ErrNotFound)
 	}
-	c.JSON(http.StatusOK, out)
+}
+
+// visible tells if the request can see the component, nodes only if their parents are visible too
+func (r *RepoHandler) visible(c *gin.Context, cmp component.Component) bool {
+	if r.preview(c) {
+		return true
+	}
+	if n, ok := cmp.(*component.Node); ok {
+		for p := n.Parent(); p != nil; p = p.Parent() {
+			if !component.Published(p) {
+				return false
+			}
+		}
+	}
+	return component.Published(cmp)
+}
+
+// Preview returns an handler that allows the users with a workflow role to see
+// the components not published, it must be used after OptionalUser
+func (r *RepoHandler) Preview(roles RoleChecker) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		v, ok := c.Get("user")
+		if !ok {
+			return
+		}
+		for _, role := range []string{auth.RoleReviewer, auth.RolePublisher} {
+			if roles.HasRole(v.(models.User), role) {
+				c.Set("preview", true)
+				return
+			}
+		}
+	}
+}
+
+// GuardStatus returns an handler that keeps the status of the existing components,
+// which changes only with a transition, and checks the status of a new component
+// as a transition from draft, an empty status being a published one.
+// It does nothing if there are no publishers, as nobody could publish.
+func (r *RepoHandler) GuardStatus(roles RoleChecker) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !roles.HasPublishers() {
+			return
+		}
+		cmp, ok := stateful(r.cmp(c))
+		if !ok {
+			return
+		}
+		if old := r.stored(c, cmp); old != nil {
+			cmp.SetState(old.State())
+			return
+		}
+		status := cmp.State()
+		switch {
+		case status == component.StatusDraft:
+			return
+		case component.IsPublished(status):
+			status = component.StatusPublished
+		}
+		switch err := canTransition(roles, r.user(c), component.StatusDraft, status); err {
+		case nil:
+		case ErrPermission:
+			r.err(c, http.StatusForbidden, err)
+		default:
+			r.err(c, http.StatusBadRequest, err)
+		}
+	}
+}
+
+// stored returns the saved version of the component, nil if it is a new one
+func (r *RepoHandler) stored(c *gin.Context, cmp component.Component) component.Stateful {
+	if hash, _ := r.repo.ComponentHash(cmp); hash == "" {
+		return nil
+	}
+	switch v := cmp.(type) {
+	case *component.Item:
+		if i := r.diff(c).Item(v.ID); i != nil {
+			return i
+		}
+	case *component.Checklist:
+		return r.diff(c).Checks()
+	case *component.Form:
+		if f := r.repo.Form(v.ID, v.Locale); f != nil {
+			return f
+		}
+	case *component.Node:
+		t := c.MustGet("type").(*component.Profile)
+		if n := r.repo.TypeNode(t.Name, v.Locale, r.nodeIDs(c)...); n != nil {
+			return n
+		}
+	}
+	return nil
+}
+
+// stateful returns the component if it has a workflow status, nodes have it
+// only if their level has a Status field
+func stateful(cmp component.Component) (component.Stateful, bool) {
+	if n, ok := cmp.(*component.Node); ok && !n.Has("Status") {
+		return nil, false
+	}
+	v, ok := cmp.(component.Stateful)
+	return v, ok
+}
+
+// Transition returns an handler that changes the status of the component,
+// if the user has the role required
+func (r *RepoHandler) Transition(roles RoleChecker) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req struct {
+			Status string `json:"status"`
+		}
+		if err := c.BindJSON(&req); err != nil {
+			r.err(c, http.StatusBadRequest, err)
+			return
+		}
+		if !component.ValidStatus(req.Status) {
+			r.err(c, http.StatusBadRequest, component.ErrStatus)
+			return
+		}
+		cmp, ok := stateful(r.cmp(c))
+		if !ok {
+			r.err(c, http.StatusBadRequest, component.ErrStatus)
+			return
+		}
+		if component.IsPublished(cmp.State()) && req.Status == component.StatusPublished || cmp.State() == req.Status {
+			c.Writer.WriteHeader(http.StatusNoContent)
+			return
+		}
+		switch err := canTransition(roles, r.user(c), cmp.State(), req.Status); err {
+		case nil:
+		case ErrPermission:
+			r.err(c, http.StatusForbidden, err)
+			return
+		default:
+			r.err(c, http.StatusConflict, err)
+			return
+		}
+		hash, err := r.repo.ComponentHash(cmp)
+		if err != nil {
+			r.err(c, http.StatusInternalServerError, err)
+			return
+		}
+		var v component.Stateful
+		switch t := cmp.(type) {
+		case *component.Item:
+			i := *t
+			i.Hash, v = hash, &i
+		case *component.Checklist:
+			l := *t
+			l.Hash, v = hash, &l
+		case *component.Form:
+			f := *t
+			f.Hash, v = hash, &f
+		case *component.Node:
+			n := t.Copy()
+			n.Hash, v = hash, n
+		}
+		v.SetState(req.Status)
+		commit, ok := r.commit(c)
+		if !ok {
+			return
+		}
+		err = r.repo.Update(v, r.user(c), r.token(c), commit)
+		r.record(c, actionUpdate, v, err)
+		if err != nil {
+			r.err(c, http.StatusInternalServerError, err)
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"status": req.Status})
+	}
+}
+
+// record adds the outcome of a write operation to the audit log
+func (r *RepoHandler) record(c *gin.Context, action int, cmp component.Component, err error) {
+	if r.repo.audit == nil {
+		return
+	}
+	r.log(c, action, r.entry(c, action, cmp), err)
+}
+
+// entry returns the audit entry of the operation on the component, with its hashes
+func (r *RepoHandler) entry(c *gin.Context, action int, cmp component.Component) audit.Entry {
+	e := audit.Entry{Path: cmp.Path()}
+	if _, ok := cmp.(*component.Asset); !ok {
+		e.Locale = r.locale(c)
+	}
+	if action != actionCreate {
+		e.OldHash = cmp.SHA()
+	}
+	if action != actionDelete {
+		if contents, err := r.repo.contents(cmp); err == nil {
+			e.NewHash = blobHash(contents)
+		}
+	}
+	return e
+}
+
+// log adds the entry to the audit log, with the action, the user and the outcome
+func (r *RepoHandler) log(c *gin.Context, action int, e audit.Entry, err error) {
+	u := r.user(c)
+	e.Action, e.User, e.Login, e.IP, e.Outcome = strings.ToLower(commitMsg[action]), u.ID, u.Login, c.ClientIP(), audit.OutcomeOK
+	if err != nil {
+		e.Outcome, e.NewHash = err.Error(), ""
+	}
+	if err := r.repo.audit.Record(e); err != nil {
+		logger.Errorf("Audit failed: %s", err)
+	}
+}
+
+// commit reads the optional commit message and co-authors from the headers
+func (r *RepoHandler) commit(c *gin.Context) (Commit, bool) {
+	commit := Commit{Message: c.Request.Header.Get(headerMessage)}
+	for _, v := range c.Request.Header[headerCoAuthor] {
+		list, err := mail.ParseAddressList(v)
+		if err != nil {
+			r.err(c, http.StatusBadRequest, fmt.Errorf("%s: %s", headerCoAuthor, err))
+			return commit, false
+		}
+		for _, a := range list {
+			commit.CoAuthors = append(commit.CoAuthors, models.User{Name: a.Name, Email: a.Address})
+		}
+	}
+	return commit, true
 }
 
 func (r *RepoHandler) Create(c *gin.Context) {
-	if err := r.repo.Create(r.cmp(c), r.user(c), r.token(c)); err != nil {
+	commit, ok := r.commit(c)
+	if !ok {
+		return
+	}
+	err := r.repo.Create(r.cmp(c), r.user(c), r.token(c), commit)
+	r.record(c, actionCreate, r.cmp(c), err)
+	if err != nil {
 		r.err(c, http.StatusInternalServerError, err)
 		return
 	}
@@ -407,7 +801,13 @@ func (r *RepoHandler) Create(c *gin.Context) {
 }
 
 func (r *RepoHandler) Update(c *gin.Context) {
-	if err := r.repo.Update(r.cmp(c), r.user(c), r.token(c)); err != nil {
+	commit, ok := r.commit(c)
+	if !ok {
+		return
+	}
+	err := r.repo.Update(r.cmp(c), r.user(c), r.token(c), commit)
+	r.record(c, actionUpdate, r.cmp(c), err)
+	if err != nil {
 		r.err(c, http.StatusInternalServerError, err)
 		return
 	}
@@ -415,7 +815,13 @@ func (r *RepoHandler) Update(c *gin.Context) {
 }
 
 func (r *RepoHandler) Delete(c *gin.Context) {
-	if err := r.repo.Delete(r.cmp(c), r.user(c), r.token(c)); err != nil {
+	commit, ok := r.commit(c)
+	if !ok {
+		return
+	}
+	err := r.repo.Delete(r.cmp(c), r.user(c), r.token(c), commit)
+	r.record(c, actionDelete, r.cmp(c), err)
+	if err != nil {
 		r.err(c, http.StatusInternalServerError, err)
 		return
 	}
@@ -439,15 +845,368 @@ func (r *RepoHandler) AssetShow(c *gin.Context) {
 
 func (r *RepoHandler) AssetCreate(c *gin.Context) {
 	asset := r.asset(c)
-	if err := r.repo.Create(asset, r.user(c), r.token(c)); err != nil {
+	commit, ok := r.commit(c)
+	if !ok {
+		return
+	}
+	err := r.repo.Create(asset, r.user(c), r.token(c), commit)
+	r.record(c, actionCreate, asset, err)
+	if err != nil {
 		r.err(c, http.StatusInternalServerError, err)
 		return
 	}
 	c.JSON(201, gin.H{"id": asset.ID})
 }
 
+// Audit lists the audit log entries, or exports them as JSON lines with format=jsonl
+func (r *RepoHandler) Audit(c *gin.Context) {
+	if r.repo.audit == nil {
+		r.err(c, http.StatusNotFound, ErrNotFound)
+		return
+	}
+	q := audit.Query{
+		User:   c.Query("user"),
+		Action: c.Query("action"),
+		Path:   c.Query("path"),
+		Locale: c.Query("locale"),
+	}
+	var err error
+	if v := c.Query("since"); v != "" {
+		if q.Since, err = time.Parse(time.RFC3339, v); err != nil {
+			r.err(c, http.StatusBadRequest, err)
+			return
+		}
+	}
+	if v := c.Query("until"); v != "" {
+		if q.Until, err = time.Parse(time.RFC3339, v); err != nil {
+			r.err(c, http.StatusBadRequest, err)
+			return
+		}
+	}
+	if v := c.Query("limit"); v != "" {
+		if q.Limit, err = strconv.Atoi(v); err != nil {
+			r.err(c, http.StatusBadRequest, err)
+			return
+		}
+	}
+	if c.Query("format") == "jsonl" {
+		c.Writer.Header().Set("Content-Type", "application/x-ndjson")
+		c.Status(http.StatusOK)
+		if err := r.repo.audit.Export(c.Writer, q); err != nil {
+			logger.Errorf("Audit export failed: %s", err)
+		}
+		return
+	}
+	entries, err := r.repo.audit.Query(q)
+	if err != nil {
+		r.err(c, http.StatusInternalServerError, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"verified": r.repo.audit.Verify() == nil,
+		"entries":  entries,
+	})
+}
+
+// Stale lists the components overdue for review, all locales or the one in the query
+func (r *RepoHandler) Stale(c *gin.Context) {
+	stale, err := r.repo.Stale(c.Query("locale"), time.Now())
+	if err != nil {
+		r.err(c, http.StatusInternalServerError, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"locales": stale})
+}
+
+// Tags lists the tags of the locale with the number of items
+func (r *RepoHandler) Tags(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"tags": r.repo.Tags(r.locale(c), r.preview(c))})
+}
+
+// Tagged lists the items of the locale with the tag, across categories
+func (r *RepoHandler) Tagged(c *gin.Context) {
+	tag := component.NormalizeTag(c.Param("tag"))
+	writeJSON(c, http.StatusOK, gin.H{"tag": tag, "items": r.repo.Tagged(r.locale(c), tag, r.preview(c))})
+}
+
+// Move relocates the component and its children, in every locale, to the
+// target in the body, the fields omitted are the ones of the component
+func (r *RepoHandler) Move(c *gin.Context) {
+	from := component.Ref{
+		Category:    c.Param("cat"),
+		Subcategory: c.Param("sub"),
+		Difficulty:  c.Param("diff"),
+		Item:        c.Param("item"),
+	}
+	var req component.Ref
+	if err := c.BindJSON(&req); err != nil {
+		r.err(c, http.StatusBadRequest, err)
+		return
+	}
+	to := from
+	for _, f := range [][2]*string{
+		{&to.Category, &req.Category},
+		{&to.Subcategory, &req.Subcategory},
+		{&to.Difficulty, &req.Difficulty},
+		{&to.Item, &req.Item},
+	}[:from.Level()] {
+		if *f[1] != "" {
+			*f[0] = *f[1]
+		}
+	}
+	commit, ok := r.commit(c)
+	if !ok {
+		return
+	}
+	err := r.repo.Move(from, to, r.user(c), r.token(c), commit)
+	if r.repo.audit != nil {
+		e := r.entry(c, actionMove, r.cmp(c))
+		e.From, e.Path = e.Path, movedPath(e.Path, from, to)
+		r.log(c, actionMove, e, err)
+	}
+	switch err {
+	case nil:
+	case ErrMove:
+		r.err(c, http.StatusBadRequest, err)
+		return
+	case ErrExists, ErrConflict:
+		r.err(c, http.StatusConflict, err)
+		return
+	case ErrTarget, ErrNotFound:
+		r.err(c, http.StatusNotFound, err)
+		return
+	case ErrLocked:
+		r.err(c, http.StatusLocked, err)
+		return
+	default:
+		r.err(c, http.StatusInternalServerError, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"from": from.Prefix(), "to": to.Prefix()})
+}
+
+// Reorder sorts the children of the component, or the categories, as the IDs in the body
+func (r *RepoHandler) Reorder(c *gin.Context) {
+	var req struct {
+		Order []string `json:"order"`
+	}
+	if err := c.BindJSON(&req); err != nil {
+		r.err(c, http.StatusBadRequest, err)
+		return
+	}
+	commit, ok := r.commit(c)
+	if !ok {
+		return
+	}
+	parent := component.Ref{
+		Category:    c.Param("cat"),
+		Subcategory: c.Param("sub"),
+		Difficulty:  c.Param("diff"),
+	}
+	err := r.repo.Reorder(parent, req.Order, r.locale(c), r.user(c), r.token(c), commit)
+	if r.repo.audit != nil {
+		e := audit.Entry{Path: "contents_" + r.locale(c), Locale: r.locale(c)}
+		if cmp := r.cmp(c); cmp != nil {
+			e.Path = cmp.Path()
+		}
+		r.log(c, actionOrder, e, err)
+	}
+	switch err {
+	case nil:
+	case ErrOrder:
+		r.err(c, http.StatusBadRequest, err)
+		return
+	case ErrNotFound:
+		r.err(c, http.StatusNotFound, err)
+		return
+	case ErrLocked:
+		r.err(c, http.StatusLocked, err)
+		return
+	case ErrConflict:
+		r.err(c, http.StatusConflict, err)
+		return
+	default:
+		r.err(c, http.StatusInternalServerError, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"order": req.Order})
+}
+
+// Nodes lists the nodes of the first level of the hierarchy
+func (r *RepoHandler) Nodes(c *gin.Context) {
+	var (
+		p      = r.repo.Profile()
+		levels = make([]string, p.Depth())
+		nodes  = make([]*component.Node, 0)
+	)
+	for i, l := range p.Levels {
+		levels[i] = l.Name
+	}
+	for _, n := range r.repo.Nodes(r.locale(c)) {
+		if r.preview(c) {
+			nodes = append(nodes, n)
+		} else if component.Published(n) {
+			nodes = append(nodes, n.Published())
+		}
+	}
+	writeJSON(c, http.StatusOK, gin.H{"profile": p.Name, "levels": levels, "nodes": nodes})
+}
+
+// ShowNode shows the node with the path of IDs
+func (r *RepoHandler) ShowNode(c *gin.Context) {
+	ids := strings.Split(strings.Trim(c.Param("path"), "/"), "/")
+	n := r.repo.Node(r.locale(c), ids...)
+	if n == nil || !r.visible(c, n) {
+		r.err(c, http.StatusNotFound, ErrNotFound)
+		return
+	}
+	hash, err := r.repo.ComponentHash(n)
+	if err != nil {
+		r.err(c, http.StatusInternalServerError, err)
+		return
+	}
+	v := *n
+	if !r.preview(c) {
+		v = *n.Published()
+	}
+	v.Hash = hash
+	writeJSON(c, http.StatusOK, r.withLease(c, n, &v))
+}
+
+// Glossary lists the terms of the locale
+func (r *RepoHandler) Glossary(c *gin.Context) {
+	terms := r.repo.Glossary(r.locale(c))
+	if terms == nil {
+		terms = make([]component.Term, 0)
+	}
+	writeJSON(c, http.StatusOK, gin.H{"terms": terms})
+}
+
+// Term shows the glossary term with the ID
+func (r *RepoHandler) Term(c *gin.Context) {
+	t := r.repo.Term(c.Param("term"), r.locale(c))
+	if t == nil {
+		r.err(c, http.StatusNotFound, ErrNotFound)
+		return
+	}
+	writeJSON(c, http.StatusOK, t)
+}
+
+// Types lists the custom component types
+func (r *RepoHandler) Types(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"types": component.Types()})
+}
+
+// SetType loads the custom component type using the url parameter
+func (r *RepoHandler) SetType(c *gin.Context) {
+	t := component.TypeByName(c.Param("type"))
+	if t == nil {
+		r.err(c, http.StatusNotFound, ErrNotFound)
+		return
+	}
+	c.Set("type", t)
+}
+
+func (r *RepoHandler) nodeIDs(c *gin.Context) []string {
+	return strings.Split(strings.Trim(c.Param("path"), "/"), "/")
+}
+
+// TypeNodes lists the nodes of the first level of the custom type
+func (r *RepoHandler) TypeNodes(c *gin.Context) {
+	t := c.MustGet("type").(*component.Profile)
+	var nodes = make([]*component.Node, 0)
+	for _, n := range r.repo.TypeNodes(t.Name, r.locale(c)) {
+		if r.preview(c) {
+			nodes = append(nodes, n)
+		} else if component.Published(n) {
+			nodes = append(nodes, n.Published())
+		}
+	}
+	writeJSON(c, http.StatusOK, gin.H{"type": t, "nodes": nodes})
+}
+
+// SetTypeNode loads the node of the custom type using the url path
+func (r *RepoHandler) SetTypeNode(c *gin.Context) {
+	t := c.MustGet("type").(*component.Profile)
+	n := r.repo.TypeNode(t.Name, r.locale(c), r.nodeIDs(c)...)
+	if n == nil {
+		r.err(c, http.StatusNotFound, ErrNotFound)
+		return
+	}
+	c.Set("node", n)
+}
+
+// ParseTypeNode creates the node of the custom type using the url path and the body
+func (r *RepoHandler) ParseTypeNode(c *gin.Context) {
+	t := c.MustGet("type").(*component.Profile)
+	n, err := t.NewNode(r.locale(c), r.nodeIDs(c)...)
+	if err != nil {
+		r.err(c, http.StatusBadRequest, err)
+		return
+	}
+	var req struct {
+		Hash  string            `json:"hash"`
+		Meta  map[string]string `json:"meta"`
+		Body  string            `json:"body"`
+		Extra component.Extra   `json:"extra"`
+	}
+	if err := c.BindJSON(&req); err != nil {
+		r.err(c, http.StatusBadRequest, err)
+		return
+	}
+	n.Hash, n.Body, n.Extra = req.Hash, req.Body, req.Extra
+	for k, v := range req.Meta {
+		if !n.Set(k, v) {
+			r.err(c, http.StatusBadRequest, fmt.Errorf("Unknown field %s", k))
+			return
+		}
+	}
+	if c.Request.Method != http.MethodDelete {
+		if err := n.Check(); err != nil {
+			r.err(c, http.StatusBadRequest, err)
+			return
+		}
+	}
+	c.Set("node", n)
+}
+
+// IsNewNode blocks the request if the node exists or its parent doesn't
+func (r *RepoHandler) IsNewNode(c *gin.Context) {
+	t, ids := c.MustGet("type").(*component.Profile), r.nodeIDs(c)
+	if r.repo.TypeNode(t.Name, r.locale(c), ids...) != nil {
+		r.err(c, http.StatusConflict, ErrExists)
+		return
+	}
+	if len(ids) > 1 && r.repo.TypeNode(t.Name, r.locale(c), ids[:len(ids)-1]...) == nil {
+		r.err(c, http.StatusNotFound, ErrNotFound)
+	}
+}
+
+// CanDeleteNode blocks the request if the node is missing or has children
+func (r *RepoHandler) CanDeleteNode(c *gin.Context) {
+	t := c.MustGet("type").(*component.Profile)
+	n := r.repo.TypeNode(t.Name, r.locale(c), r.nodeIDs(c)...)
+	if n == nil {
+		r.err(c, http.StatusNotFound, ErrNotFound)
+		return
+	}
+	if n.HasChildren() {
+		r.err(c, http.StatusForbidden, ErrHasChildren)
+	}
+}
+
+// References lists the references to missing items, all locales or the one in the query
+func (r *RepoHandler) References(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"errors": r.repo.Dangling(c.Query("locale"))})
+}
+
+// Orphans lists the nodes skipped for a missing parent, all locales or the one in the query
+func (r *RepoHandler) Orphans(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"errors": r.repo.Orphans(c.Query("locale"))})
+}
+
 func (r *RepoHandler) Tree(c *gin.Context) {
-	writeJSON(c, http.StatusOK, r.repo.Tree(r.locale(c), c.Query("content") == "html"))
+	writeJSON(c, http.StatusOK, r.repo.Tree(r.locale(c), c.Query("content") == "html", r.preview(c)))
 }
 
 func writeJSON(c *gin.Context, status int, obj interface{}) {
@@ -0,0 +1,137 @@
+package repo
+
+import (
+	"io"
+	"sort"
+	"time"
+
+	"github.com/securityfirst/tent/component"
+	"gopkg.in/src-d/go-git.v4/plumbing"
+	"gopkg.in/src-d/go-git.v4/plumbing/object"
+)
+
+// StaleEntry is a component overdue for review, with its last change
+type StaleEntry struct {
+	Path         string     `json:"path"`
+	Title        string     `json:"title,omitempty"`
+	LastReviewed *time.Time `json:"last_reviewed,omitempty"`
+	ReviewEvery  int        `json:"review_every"`
+	Due          *time.Time `json:"due,omitempty"`
+	Author       string     `json:"author,omitempty"`
+	Email        string     `json:"email,omitempty"`
+	Date         *time.Time `json:"date,omitempty"`
+	Commit       string     `json:"commit,omitempty"`
+}
+
+// Stale returns the components overdue for review by locale, all the locales if empty
+func (r *Repo) Stale(locale string, now time.Time) (map[string][]StaleEntry, error) {
+	r.RLock()
+	head := r.commit
+	if head == nil {
+		r.RUnlock()
+		return nil, ErrNotReady
+	}
+	var (
+		result = make(map[string][]StaleEntry)
+		index  = make(map[string]*StaleEntry)
+	)
+	for _, l := range r.Locale() {
+		if locale != "" && l != locale {
+			continue
+		}
+		list := make([]StaleEntry, 0)
+		for _, c := range r.All(l) {
+			if !component.Stale(c, now) {
+				continue
+			}
+			e := StaleEntry{Path: c.Path()}
+			e.LastReviewed, e.ReviewEvery = c.(component.Reviewable).Review()
+			if due, _ := component.ReviewDue(c); !due.IsZero() {
+				e.Due = &due
+			}
+			if i, ok := c.(*component.Item); ok {
+				e.Title = i.Title
+			}
+			list = append(list, e)
+		}
+		result[l] = list
+	}
+	r.RUnlock()
+	for l := range result {
+		for i := range result[l] {
+			index[result[l][i].Path] = &result[l][i]
+		}
+	}
+	if err := lastChanges(head, index); err != nil {
+		return nil, err
+	}
+	for l := range result {
+		sort.Slice(result[l], func(i, j int) bool { return result[l][i].Path < result[l][j].Path })
+	}
+	return result, nil
+}
+
+// lastChanges sets the last commit that changed each path, walking the history
+// from the head until all are found. The change is the commit that introduced the
+// current version of the file, the one of no parent, so merges are compared with all.
+func lastChanges(head *object.Commit, entries map[string]*StaleEntry) error {
+	tree, err := head.Tree()
+	if err != nil {
+		return err
+	}
+	current := make(map[string]plumbing.Hash, len(entries))
+	for path := range entries {
+		if f, err := tree.FindEntry(path); err == nil {
+			current[path] = f.Hash
+		}
+	}
+	iter := object.NewCommitPreorderIter(head, nil, nil)
+	defer iter.Close()
+	for missing := len(current); missing > 0; {
+		c, err := iter.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if tree, err = c.Tree(); err != nil {
+			return err
+		}
+		parents := make([]*object.Tree, 0, c.NumParents())
+		err = c.Parents().ForEach(func(p *object.Commit) error {
+			t, err := p.Tree()
+			if err != nil {
+				return err
+			}
+			parents = append(parents, t)
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		for path, hash := range current {
+			if !introduced(tree, parents, path, hash) {
+				continue
+			}
+			e, date := entries[path], c.Author.When
+			e.Author, e.Email, e.Date, e.Commit = c.Author.Name, c.Author.Email, &date, c.Hash.String()
+			delete(current, path)
+			missing--
+		}
+	}
+	return nil
+}
+
+// introduced tells if the tree has the version of the file and none of the parents has it
+func introduced(tree *object.Tree, parents []*object.Tree, path string, hash plumbing.Hash) bool {
+	if f, err := tree.FindEntry(path); err != nil || f.Hash != hash {
+		return false
+	}
+	for _, p := range parents {
+		if f, err := p.FindEntry(path); err == nil && f.Hash == hash {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,78 @@
+package repo
+
+import (
+	"sort"
+	"testing"
+	"time"
+
+	"gopkg.in/src-d/go-git.v4/plumbing"
+	"gopkg.in/src-d/go-git.v4/plumbing/filemode"
+	"gopkg.in/src-d/go-git.v4/plumbing/object"
+	"gopkg.in/src-d/go-git.v4/storage/memory"
+)
+
+// testCommit stores a commit with the files, at the root of the tree, and the parents
+func testCommit(t *testing.T, s *memory.Storage, name string, files map[string]string, parents ...*object.Commit) *object.Commit {
+	var tree object.Tree
+	for file, contents := range files {
+		obj := s.NewEncodedObject()
+		obj.SetType(plumbing.BlobObject)
+		w, _ := obj.Writer()
+		w.Write([]byte(contents))
+		w.Close()
+		hash, err := s.SetEncodedObject(obj)
+		if err != nil {
+			t.Fatal(err)
+		}
+		tree.Entries = append(tree.Entries, object.TreeEntry{Name: file, Mode: filemode.Regular, Hash: hash})
+	}
+	sort.Slice(tree.Entries, func(i, j int) bool { return tree.Entries[i].Name < tree.Entries[j].Name })
+	obj := s.NewEncodedObject()
+	if err := tree.Encode(obj); err != nil {
+		t.Fatal(err)
+	}
+	treeHash, err := s.SetEncodedObject(obj)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := object.Commit{
+		Author:    object.Signature{Name: name, Email: name + "@tent.org", When: time.Now()},
+		Committer: object.Signature{Name: name, Email: name + "@tent.org", When: time.Now()},
+		Message:   name,
+		TreeHash:  treeHash,
+	}
+	for _, p := range parents {
+		c.ParentHashes = append(c.ParentHashes, p.Hash)
+	}
+	obj = s.NewEncodedObject()
+	if err := c.Encode(obj); err != nil {
+		t.Fatal(err)
+	}
+	hash, err := s.SetEncodedObject(obj)
+	if err != nil {
+		t.Fatal(err)
+	}
+	v, err := object.GetCommit(s, hash)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return v
+}
+
+func TestLastChanges(t *testing.T) {
+	s := memory.NewStorage()
+	root := testCommit(t, s, "root", map[string]string{"a.md": "a1", "b.md": "b1", "c.md": "c1"})
+	main := testCommit(t, s, "main", map[string]string{"a.md": "a2", "b.md": "b1", "c.md": "c1"}, root)
+	branch := testCommit(t, s, "branch", map[string]string{"a.md": "a1", "b.md": "b2", "c.md": "c1"}, root)
+	merge := testCommit(t, s, "merge", map[string]string{"a.md": "a2", "b.md": "b2", "c.md": "c1"}, main, branch)
+
+	entries := map[string]*StaleEntry{"a.md": {}, "b.md": {}, "c.md": {}}
+	if err := lastChanges(merge, entries); err != nil {
+		t.Fatal(err)
+	}
+	for path, want := range map[string]string{"a.md": "main", "b.md": "branch", "c.md": "root"} {
+		if e := entries[path]; e.Author != want {
+			t.Errorf("%s: expected change by %s, got %q", path, want, e.Author)
+		}
+	}
+}
@@ -0,0 +1,151 @@
+package repo
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/sha512"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+
+	"golang.org/x/crypto/openpgp"
+	"golang.org/x/crypto/ssh"
+)
+
+var ErrSignKey = errors.New("invalid signing key")
+
+// SignConf contains the key used to sign commits, a GPG or a SSH one
+type SignConf struct {
+	GPGKey     string
+	SSHKey     string
+	Passphrase string
+}
+
+// signer creates the signature of a commit
+type signer interface {
+	Sign(payload []byte) (string, error)
+}
+
+func (c *SignConf) signer() (signer, error) {
+	switch {
+	case c.GPGKey != "" && c.SSHKey != "":
+		return nil, fmt.Errorf("%s: both GPG and SSH key", ErrSignKey)
+	case c.GPGKey != "":
+		return newGPGSigner(c.GPGKey, c.Passphrase)
+	case c.SSHKey != "":
+		return newSSHSigner(c.SSHKey, c.Passphrase)
+	}
+	return nil, nil
+}
+
+type gpgSigner struct {
+	entity *openpgp.Entity
+}
+
+func newGPGSigner(path, passphrase string) (*gpgSigner, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	list, err := openpgp.ReadArmoredKeyRing(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %s", ErrSignKey, err)
+	}
+	if len(list) == 0 || list[0].PrivateKey == nil {
+		return nil, fmt.Errorf("%s: no private key", ErrSignKey)
+	}
+	e := list[0]
+	if e.PrivateKey.Encrypted {
+		if err := e.PrivateKey.Decrypt([]byte(passphrase)); err != nil {
+			return nil, fmt.Errorf("%s: %s", ErrSignKey, err)
+		}
+	}
+	for _, s := range e.Subkeys {
+		if s.PrivateKey != nil && s.PrivateKey.Encrypted {
+			if err := s.PrivateKey.Decrypt([]byte(passphrase)); err != nil {
+				return nil, fmt.Errorf("%s: %s", ErrSignKey, err)
+			}
+		}
+	}
+	return &gpgSigner{entity: e}, nil
+}
+
+func (s *gpgSigner) Sign(payload []byte) (string, error) {
+	b := bytes.NewBuffer(nil)
+	if err := openpgp.ArmoredDetachSign(b, s.entity, bytes.NewReader(payload), nil); err != nil {
+		return "", err
+	}
+	return b.String(), nil
+}
+
+// sshSigner creates signatures in the format used by git (ssh-keygen -Y sign)
+type sshSigner struct {
+	signer ssh.Signer
+}
+
+const (
+	sshsigMagic     = "SSHSIG"
+	sshsigNamespace = "git"
+	sshsigHash      = "sha512"
+)
+
+func newSSHSigner(path, passphrase string) (*sshSigner, error) {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var s ssh.Signer
+	if passphrase != "" {
+		s, err = ssh.ParsePrivateKeyWithPassphrase(b, []byte(passphrase))
+	} else {
+		s, err = ssh.ParsePrivateKey(b)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %s", ErrSignKey, err)
+	}
+	return &sshSigner{signer: s}, nil
+}
+
+func (s *sshSigner) Sign(payload []byte) (string, error) {
+	sum := sha512.Sum512(payload)
+	data := append([]byte(sshsigMagic), ssh.Marshal(struct {
+		Namespace string
+		Reserved  string
+		Hash      string
+		Digest    []byte
+	}{sshsigNamespace, "", sshsigHash, sum[:]})...)
+
+	var (
+		sig *ssh.Signature
+		err error
+	)
+	if a, ok := s.signer.(ssh.AlgorithmSigner); ok && s.signer.PublicKey().Type() == ssh.KeyAlgoRSA {
+		sig, err = a.SignWithAlgorithm(rand.Reader, data, ssh.SigAlgoRSASHA2512)
+	} else {
+		sig, err = s.signer.Sign(rand.Reader, data)
+	}
+	if err != nil {
+		return "", err
+	}
+	blob := append([]byte(sshsigMagic), ssh.Marshal(struct {
+		Version   uint32
+		PublicKey []byte
+		Namespace string
+		Reserved  string
+		Hash      string
+		Signature []byte
+	}{1, s.signer.PublicKey().Marshal(), sshsigNamespace, "", sshsigHash, ssh.Marshal(sig)})...)
+
+	enc := base64.StdEncoding.EncodeToString(blob)
+	b := bytes.NewBufferString("-----BEGIN SSH SIGNATURE-----\n")
+	for len(enc) > 70 {
+		b.WriteString(enc[:70] + "\n")
+		enc = enc[70:]
+	}
+	b.WriteString(enc + "\n-----END SSH SIGNATURE-----")
+	return strings.TrimSpace(b.String()), nil
+}
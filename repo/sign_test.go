@@ -0,0 +1,107 @@
+package repo
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha512"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"golang.org/x/crypto/ssh"
+)
+
+func TestSSHSigner(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir, err := ioutil.TempDir("", "sign")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "id_rsa")
+	b := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})
+	if err := ioutil.WriteFile(path, b, 0600); err != nil {
+		t.Fatal(err)
+	}
+	s, err := (&SignConf{SSHKey: path}).signer()
+	if err != nil {
+		t.Fatal(err)
+	}
+	payload := []byte("tree 123\n\ncommit message\n")
+	armored, err := s.Sign(payload)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(armored, "\n")
+	if lines[0] != "-----BEGIN SSH SIGNATURE-----" || lines[len(lines)-1] != "-----END SSH SIGNATURE-----" {
+		t.Fatalf("unexpected armor %q", armored)
+	}
+	for _, l := range lines[1 : len(lines)-1] {
+		if len(l) > 70 {
+			t.Errorf("line too long %q", l)
+		}
+	}
+	blob, err := base64.StdEncoding.DecodeString(strings.Join(lines[1:len(lines)-1], ""))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.HasPrefix(blob, []byte(sshsigMagic)) {
+		t.Fatalf("missing magic")
+	}
+	var sig struct {
+		Version   uint32
+		PublicKey []byte
+		Namespace string
+		Reserved  string
+		Hash      string
+		Signature []byte
+	}
+	if err := ssh.Unmarshal(blob[len(sshsigMagic):], &sig); err != nil {
+		t.Fatal(err)
+	}
+	if sig.Version != 1 || sig.Namespace != "git" || sig.Hash != "sha512" {
+		t.Errorf("unexpected signature %+v", sig)
+	}
+	pub, err := ssh.ParsePublicKey(sig.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var signature ssh.Signature
+	if err := ssh.Unmarshal(sig.Signature, &signature); err != nil {
+		t.Fatal(err)
+	}
+	if signature.Format != ssh.SigAlgoRSASHA2512 {
+		t.Errorf("unexpected format %s", signature.Format)
+	}
+	sum := sha512.Sum512(payload)
+	data := append([]byte(sshsigMagic), ssh.Marshal(struct {
+		Namespace string
+		Reserved  string
+		Hash      string
+		Digest    []byte
+	}{"git", "", "sha512", sum[:]})...)
+	if err := pub.Verify(data, &signature); err != nil {
+		t.Errorf("invalid signature: %s", err)
+	}
+	if err := pub.Verify(append(data, 0), &signature); err == nil {
+		t.Error("expected invalid signature for other data")
+	}
+}
+
+func TestSignConf(t *testing.T) {
+	if s, err := (&SignConf{}).signer(); s != nil || err != nil {
+		t.Errorf("unexpected signer %v: %v", s, err)
+	}
+	if _, err := (&SignConf{GPGKey: "a", SSHKey: "b"}).signer(); err == nil {
+		t.Error("expected error with both keys")
+	}
+}
@@ -0,0 +1,90 @@
+package repo
+
+import (
+	"sync"
+
+	"gopkg.in/src-d/go-git.v4/plumbing"
+	"gopkg.in/src-d/go-git.v4/plumbing/storer"
+	"gopkg.in/src-d/go-git.v4/storage/memory"
+)
+
+// syncStorage is a memory storage safe for concurrent use, so that fetch and
+// push can run without holding the lock of the repository
+type syncStorage struct {
+	mu sync.RWMutex
+	*memory.Storage
+}
+
+func newStorage() *syncStorage { return &syncStorage{Storage: memory.NewStorage()} }
+
+func (s *syncStorage) SetEncodedObject(obj plumbing.EncodedObject) (plumbing.Hash, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.Storage.SetEncodedObject(obj)
+}
+
+func (s *syncStorage) EncodedObject(t plumbing.ObjectType, h plumbing.Hash) (plumbing.EncodedObject, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.Storage.EncodedObject(t, h)
+}
+
+func (s *syncStorage) IterEncodedObjects(t plumbing.ObjectType) (storer.EncodedObjectIter, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.Storage.IterEncodedObjects(t)
+}
+
+func (s *syncStorage) HasEncodedObject(h plumbing.Hash) error {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.Storage.HasEncodedObject(h)
+}
+
+func (s *syncStorage) EncodedObjectSize(h plumbing.Hash) (int64, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.Storage.EncodedObjectSize(h)
+}
+
+func (s *syncStorage) ForEachObjectHash(fn func(plumbing.Hash) error) error {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.Storage.ForEachObjectHash(fn)
+}
+
+func (s *syncStorage) SetReference(ref *plumbing.Reference) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.Storage.SetReference(ref)
+}
+
+func (s *syncStorage) CheckAndSetReference(ref, old *plumbing.Reference) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.Storage.CheckAndSetReference(ref, old)
+}
+
+func (s *syncStorage) Reference(n plumbing.ReferenceName) (*plumbing.Reference, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.Storage.Reference(n)
+}
+
+func (s *syncStorage) IterReferences() (storer.ReferenceIter, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.Storage.IterReferences()
+}
+
+func (s *syncStorage) RemoveReference(n plumbing.ReferenceName) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.Storage.RemoveReference(n)
+}
+
+func (s *syncStorage) CountLooseRefs() (int, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.Storage.CountLooseRefs()
+}
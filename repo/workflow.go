@@ -0,0 +1,53 @@
+package repo
+
+import (
+	"errors"
+
+	"github.com/securityfirst/tent/auth"
+	"github.com/securityfirst/tent/component"
+	"github.com/securityfirst/tent/models"
+)
+
+var ErrTransition = errors.New("invalid transition")
+
+// RoleChecker tells if a user has a role, and if anybody can publish
+type RoleChecker interface {
+	HasRole(u models.User, role string) bool
+	HasPublishers() bool
+}
+
+// transitions contains the roles allowed to change status, nil allows all editors
+var transitions = map[string]map[string][]string{
+	component.StatusDraft: {
+		component.StatusReview:    nil,
+		component.StatusPublished: {auth.RolePublisher},
+	},
+	component.StatusReview: {
+		component.StatusDraft:     {auth.RoleReviewer, auth.RolePublisher},
+		component.StatusPublished: {auth.RolePublisher},
+	},
+	component.StatusPublished: {
+		component.StatusDraft:  {auth.RolePublisher},
+		component.StatusReview: {auth.RolePublisher},
+	},
+}
+
+// canTransition checks if the user can move a component from a status to another
+func canTransition(roles RoleChecker, u models.User, from, to string) error {
+	if from == "" {
+		from = component.StatusPublished
+	}
+	allowed, ok := transitions[from][to]
+	if !ok {
+		return ErrTransition
+	}
+	if allowed == nil {
+		return nil
+	}
+	for _, role := range allowed {
+		if roles.HasRole(u, role) {
+			return nil
+		}
+	}
+	return ErrPermission
+}
@@ -0,0 +1,226 @@
+package repo
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/securityfirst/tent/auth"
+	"github.com/securityfirst/tent/component"
+	"github.com/securityfirst/tent/models"
+)
+
+// testRoles gives the roles to the users by ID
+type testRoles map[string][]string
+
+func (t testRoles) HasPublishers() bool {
+	for _, roles := range t {
+		for _, r := range roles {
+			if r == auth.RolePublisher || r == auth.RoleAdmin {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (t testRoles) HasRole(u models.User, role string) bool {
+	for _, r := range t[u.ID] {
+		if r == role {
+			return true
+		}
+	}
+	return false
+}
+
+var (
+	testEditor    = models.User{ID: "github:editor", Login: "editor"}
+	testPublisher = models.User{ID: "github:publisher", Login: "publisher"}
+	testKey       = models.User{ID: "key:ci", Login: "bot"}
+	roles         = testRoles{testPublisher.ID: {auth.RolePublisher}}
+)
+
+func TestGuardStatus(t *testing.T) {
+	stored := component.Form{ID: "form", Locale: "en", Name: "Form", Status: component.StatusDraft}
+	r, dir := newTestRepo(t, map[string]string{stored.Path(): stored.Contents()})
+	defer os.RemoveAll(dir)
+	h := r.Handler()
+
+	for _, tc := range []struct {
+		roles      testRoles
+		id, status string
+		user       models.User
+		code       int
+		want       string
+	}{
+		{roles, "form", component.StatusPublished, testPublisher, 0, component.StatusDraft},
+		{roles, "form", "", testEditor, 0, component.StatusDraft},
+		{roles, "new", "", testEditor, http.StatusForbidden, ""},
+		{roles, "new", component.StatusPublished, testEditor, http.StatusForbidden, component.StatusPublished},
+		{roles, "new", component.StatusReview, testEditor, 0, component.StatusReview},
+		{roles, "new", component.StatusPublished, testPublisher, 0, component.StatusPublished},
+		// without publishers the workflow is not enforced
+		{testRoles{}, "new", "", testEditor, 0, ""},
+		{testRoles{}, "new", component.StatusPublished, testEditor, 0, component.StatusPublished},
+		{testRoles{}, "form", component.StatusPublished, testEditor, 0, component.StatusPublished},
+	} {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		f := &component.Form{ID: tc.id, Locale: "en", Name: "Form", Status: tc.status}
+		c.Set("user", tc.user)
+		c.Set("locale", "en")
+		c.Set("form", f)
+		h.GuardStatus(tc.roles)(c)
+		if code := 0; c.IsAborted() {
+			code = w.Code
+			if code != tc.code {
+				t.Errorf("%s %q by %s: expected %d, got %d", tc.id, tc.status, tc.user.Login, tc.code, code)
+			}
+		} else if tc.code != 0 {
+			t.Errorf("%s %q by %s: expected %d", tc.id, tc.status, tc.user.Login, tc.code)
+		}
+		if f.Status != tc.want {
+			t.Errorf("%s %q by %s: expected status %q, got %q", tc.id, tc.status, tc.user.Login, tc.want, f.Status)
+		}
+	}
+}
+
+func TestParseSchedule(t *testing.T) {
+	var h RepoHandler
+	for _, tc := range []struct {
+		body string
+		code int
+	}{
+		{`{"name":"Form","publish_at":"2019-03-01T00:00:00Z","expire_at":"2019-04-01T00:00:00Z"}`, 0},
+		{`{"name":"Form","publish_at":"2019-04-01T00:00:00Z","expire_at":"2019-03-01T00:00:00Z"}`, http.StatusBadRequest},
+		{`{"name":"Form","expire_at":"2019-03-01T00:00:00Z"}`, 0},
+	} {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest(http.MethodPut, "/api/repo/forms/form", strings.NewReader(tc.body))
+		c.Request.Header.Set("Content-Type", "application/json")
+		c.Set("locale", "en")
+		h.ParseForm(c)
+		if code := 0; c.IsAborted() {
+			code = w.Code
+			if code != tc.code {
+				t.Errorf("%s: expected %d, got %d", tc.body, tc.code, code)
+			}
+		} else if tc.code != 0 {
+			t.Errorf("%s: expected %d", tc.body, tc.code)
+		}
+	}
+}
+
+func TestPreview(t *testing.T) {
+	var h RepoHandler
+	for _, tc := range []struct {
+		user *models.User
+		want bool
+	}{
+		{nil, false},
+		{&testEditor, false},
+		{&testKey, false},
+		{&testPublisher, true},
+	} {
+		c, _ := gin.CreateTestContext(httptest.NewRecorder())
+		if tc.user != nil {
+			c.Set("user", *tc.user)
+		}
+		h.Preview(roles)(c)
+		if got := h.preview(c); got != tc.want {
+			t.Errorf("%v: expected preview %v, got %v", tc.user, tc.want, got)
+		}
+	}
+}
+
+func TestStateful(t *testing.T) {
+	p := component.Profile{Name: "guides", Root: "guides", Levels: []component.Level{
+		{Name: "section", Fields: []string{"Name", "Order"}},
+		{Name: "page", Fields: []string{"Title", "Order", "Status"}, Optionals: []string{"Status"}},
+	}}
+	section, _ := p.NewNode("en", "intro")
+	page, _ := p.NewNode("en", "intro", "start")
+	for _, tc := range []struct {
+		cmp  component.Component
+		want bool
+	}{
+		{section, false},
+		{page, true},
+		{&component.Form{}, true},
+		{&component.Category{}, false},
+	} {
+		if _, ok := stateful(tc.cmp); ok != tc.want {
+			t.Errorf("%T: expected %v, got %v", tc.cmp, tc.want, ok)
+		}
+	}
+	v := page.Copy()
+	v.SetState(component.StatusPublished)
+	if page.State() != "" || v.State() != component.StatusPublished {
+		t.Errorf("expected a copy, got %q and %q", page.State(), v.State())
+	}
+}
+
+func TestNodeVisibility(t *testing.T) {
+	r, dir := newTestRepo(t, map[string]string{
+		"guides_en/intro/.metadata.md": "[Name]: # (Intro)\n[Order]: # (1)",
+		"guides_en/intro/start.md":     "[Title]: # (Start)\n[Order]: # (1)\n\nBody",
+		"guides_en/intro/next.md":      "[Title]: # (Next)\n[Order]: # (2)\n[Status]: # (draft)\n\nBody",
+		"guides_en/wip/.metadata.md":   "[Name]: # (WIP)\n[Order]: # (2)\n[Status]: # (draft)",
+		"guides_en/wip/page.md":        "[Title]: # (Page)\n[Order]: # (1)\n\nBody",
+		"guides_en/lost/page.md":       "[Title]: # (Lost)\n[Order]: # (1)\n\nBody",
+	})
+	defer os.RemoveAll(dir)
+	err := r.SetProfile(component.Profile{Name: "guides", Root: "guides", Levels: []component.Level{
+		{Name: "section", Fields: []string{"Name", "Order", "Status"}, Optionals: []string{"Status"}},
+		{Name: "page", Fields: []string{"Title", "Order", "Status"}, Optionals: []string{"Status"}},
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	r.commit = nil
+	r.Pull()
+	if o := r.Orphans("en"); len(o) != 1 || o[0].Path != "guides_en/lost/page.md" {
+		t.Errorf("unexpected orphans %v", o)
+	}
+	if n := r.Nodes("en"); len(n) != 2 {
+		t.Fatalf("expected 2 sections, got %d", len(n))
+	}
+	h := r.Handler()
+	for _, tc := range []struct {
+		path    string
+		preview bool
+		code    int
+		hidden  string
+	}{
+		{"/intro", false, http.StatusOK, "next"},
+		{"/intro", true, http.StatusOK, ""},
+		{"/intro/next", false, http.StatusNotFound, ""},
+		{"/wip/page", false, http.StatusNotFound, ""},
+		{"/wip/page", true, http.StatusOK, ""},
+	} {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Set("locale", "en")
+		c.Set("preview", tc.preview)
+		c.Params = gin.Params{{Key: "path", Value: tc.path}}
+		h.ShowNode(c)
+		if w.Code != tc.code {
+			t.Errorf("%s (preview %v): expected %d, got %d", tc.path, tc.preview, tc.code, w.Code)
+		}
+		if tc.hidden != "" && strings.Contains(w.Body.String(), `"`+tc.hidden+`"`) {
+			t.Errorf("%s: unexpected %s in %s", tc.path, tc.hidden, w.Body)
+		}
+	}
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Set("locale", "en")
+	h.Nodes(c)
+	if body := w.Body.String(); strings.Contains(body, `"next"`) || strings.Contains(body, `"wip"`) || !strings.Contains(body, `"start"`) {
+		t.Errorf("unexpected nodes %s", body)
+	}
+}
@@ -1,6 +1,7 @@
 package tent
 
 import (
+	"strings"
 	"time"
 
 	"log"
@@ -23,6 +24,26 @@ const (
 	pathAsset       = "/api/repo/asset"
 	pathAssetID     = "/api/repo/asset/:asset"
 	pathForm        = "/api/repo/form/:form"
+	pathFormSchema  = "/api/repo/form/:form/schema"
+	pathFormCheck   = "/api/repo/form/:form/validate"
+	pathAudit       = "/api/audit"
+	pathLock        = "/api/repo/lock"
+	pathStatus      = "/api/repo/status"
+	pathMove        = "/api/repo/move"
+	pathOrder       = "/api/repo/order"
+	pathStale       = "/api/reports/stale"
+	pathReferences  = "/api/reports/references"
+	pathOrphans     = "/api/reports/orphans"
+	pathTags        = "/api/tags"
+	pathNodes       = "/api/nodes"
+	pathNode        = "/api/nodes/*path"
+	pathTypes       = "/api/types"
+	pathGlossary    = "/api/glossary"
+	pathTerm        = "/api/glossary/:term"
+	pathType        = "/api/types/:type"
+	pathTypeNode    = "/api/types/:type/*path"
+	pathTypeStatus  = "/api/repo/status/types/:type/*path"
+	pathTag         = "/api/tags/:tag"
 )
 
 func New(r *repo.Repo) *Tent {
@@ -33,13 +54,17 @@ type Tent struct {
 	repo *repo.Repo
 }
 
-func (o *Tent) Register(root *gin.RouterGroup, c auth.Config) {
+func (o *Tent) Register(root *gin.RouterGroup, c auth.Config) error {
+	engine, err := auth.NewEngine(c, root)
+	if err != nil {
+		return err
+	}
 	var (
-		engine = auth.NewEngine(c, root)
 		hookCh = make(chan struct{})
 		h      = o.repo.Handler()
 	)
 	o.repo.SetConf(c.OAuth(root))
+	o.repo.SetBasePath(root.BasePath())
 
 	// Free handlers
 	root.POST(pathUpdate, func(*gin.Context) { // Hook for github
@@ -48,51 +73,102 @@ func (o *Tent) Register(root *gin.RouterGroup, c auth.Config) {
 		default: // discard
 		}
 	})
+	preview := h.Preview(engine)
 	locale := root.Use(h.ParseLocale)
-	locale.GET(pathTree, h.Tree)
+	locale.GET(pathTree, engine.OptionalUser, preview, h.Tree)
 	locale.GET(pathInfo, h.Info)
 	locale.GET(pathRepo, h.Root)
-	locale.GET(pathCategory, h.SetCat, h.Show)
-	locale.GET(pathSubcategory, h.SetSub, h.Show)
-	locale.GET(pathDifficulty, h.SetDiff, h.Show)
-	locale.GET(pathItem, h.SetItem, h.Show)
-	locale.GET(pathCheck, h.SetCheck, h.ShowChecks)
+	locale.GET(pathCategory, engine.OptionalUser, h.SetCat, h.Show)
+	locale.GET(pathSubcategory, engine.OptionalUser, h.SetSub, h.Show)
+	locale.GET(pathDifficulty, engine.OptionalUser, preview, h.SetDiff, h.Show)
+	locale.GET(pathItem, engine.OptionalUser, preview, h.SetItem, h.IsVisible, h.Show)
+	locale.GET(pathCheck, engine.OptionalUser, preview, h.SetCheck, h.IsVisible, h.ShowChecks)
 	locale.GET(pathAssetID, h.SetAsset, h.AssetShow)
-	locale.GET(pathForm, h.SetForm, h.Show)
+	locale.GET(pathForm, engine.OptionalUser, preview, h.SetForm, h.IsVisible, h.Show)
+	locale.GET(pathFormSchema, engine.OptionalUser, preview, h.SetForm, h.IsVisible, h.FormSchema)
+	locale.POST(pathFormCheck, engine.OptionalUser, preview, h.SetForm, h.IsVisible, h.FormValidate)
+	locale.GET(pathTags, engine.OptionalUser, preview, h.Tags)
+	locale.GET(pathTag, engine.OptionalUser, preview, h.Tagged)
+	locale.GET(pathNodes, engine.OptionalUser, preview, h.Nodes)
+	locale.GET(pathNode, engine.OptionalUser, preview, h.ShowNode)
+	locale.GET(pathTypes, h.Types)
+	locale.GET(pathGlossary, h.Glossary)
+	locale.GET(pathTerm, h.Term)
+	locale.GET(pathType, engine.OptionalUser, preview, h.SetType, h.TypeNodes)
+	locale.GET(pathTypeNode, engine.OptionalUser, preview, h.SetType, h.SetTypeNode, h.IsVisible, h.Show)
 
 	// Locale and Authorized handlers
 	authorized := root.Use(engine.EnsureUser, h.ParseLocale)
 
-	authorized.PUT(pathCategory, h.ParseCat, h.Update)
-	authorized.DELETE(pathCategory, h.ParseCat, h.CanDelete, h.Delete)
+	authorized.PUT(pathOrder, h.Reorder)
+
+	authorized.PUT(pathCategory, h.ParseCat, h.IsUnlocked, h.Update)
+	authorized.DELETE(pathCategory, h.ParseCat, h.CanDelete, h.IsUnlocked, h.Delete)
 	authorized.POST(pathCategory, h.ParseCat, h.IsNew, h.Create)
+	authorized.POST(actionPath(pathLock, pathCategory), h.SetCat, h.Lock)
+	authorized.DELETE(actionPath(pathLock, pathCategory), h.SetCat, h.Unlock)
+	authorized.POST(actionPath(pathMove, pathCategory), h.SetCat, h.IsUnlocked, h.Move)
+	authorized.PUT(actionPath(pathOrder, pathCategory), h.SetCat, h.Reorder)
 
-	authorized.PUT(pathSubcategory, h.ParseSub, h.Update)
-	authorized.DELETE(pathSubcategory, h.ParseSub, h.CanDelete, h.Delete)
+	authorized.PUT(pathSubcategory, h.ParseSub, h.IsUnlocked, h.Update)
+	authorized.DELETE(pathSubcategory, h.ParseSub, h.CanDelete, h.IsUnlocked, h.Delete)
 	authorized.POST(pathSubcategory, h.ParseSub, h.IsNew, h.Create)
+	authorized.POST(actionPath(pathLock, pathSubcategory), h.SetSub, h.Lock)
+	authorized.DELETE(actionPath(pathLock, pathSubcategory), h.SetSub, h.Unlock)
+	authorized.POST(actionPath(pathMove, pathSubcategory), h.SetSub, h.IsUnlocked, h.Move)
+	authorized.PUT(actionPath(pathOrder, pathSubcategory), h.SetSub, h.Reorder)
 
-	authorized.PUT(pathDifficulty, h.ParseDiff, h.Update)
-	authorized.DELETE(pathDifficulty, h.ParseDiff, h.CanDelete, h.Delete)
+	authorized.PUT(pathDifficulty, h.ParseDiff, h.IsUnlocked, h.Update)
+	authorized.DELETE(pathDifficulty, h.ParseDiff, h.CanDelete, h.IsUnlocked, h.Delete)
 	authorized.POST(pathDifficulty, h.ParseDiff, h.IsNew, h.Create)
+	authorized.POST(actionPath(pathLock, pathDifficulty), h.SetDiff, h.Lock)
+	authorized.DELETE(actionPath(pathLock, pathDifficulty), h.SetDiff, h.Unlock)
+	authorized.POST(actionPath(pathMove, pathDifficulty), h.SetDiff, h.IsUnlocked, h.Move)
+	authorized.PUT(actionPath(pathOrder, pathDifficulty), h.SetDiff, h.Reorder)
+
+	authorized.PUT(pathItem, h.ParseItem, h.IsUnlocked, h.GuardStatus(engine), h.Update)
+	authorized.DELETE(pathItem, h.ParseItem, h.CanDelete, h.IsUnlocked, h.Delete)
+	authorized.POST(pathItem, h.ParseItem, h.IsNew, h.GuardStatus(engine), h.Create)
+	authorized.POST(actionPath(pathLock, pathItem), h.SetItem, h.Lock)
+	authorized.DELETE(actionPath(pathLock, pathItem), h.SetItem, h.Unlock)
+	authorized.POST(actionPath(pathMove, pathItem), h.SetItem, h.IsUnlocked, h.Move)
+	authorized.POST(actionPath(pathStatus, pathItem), h.SetItem, h.IsUnlocked, h.Transition(engine))
+
+	authorized.PUT(pathCheck, h.ParseCheck, h.IsUnlocked, h.GuardStatus(engine), h.UpdateChecks)
+	authorized.POST(actionPath(pathLock, pathCheck), h.SetCheck, h.Lock)
+	authorized.DELETE(actionPath(pathLock, pathCheck), h.SetCheck, h.Unlock)
+	authorized.POST(actionPath(pathStatus, pathCheck), h.SetCheck, h.IsUnlocked, h.Transition(engine))
 
-	authorized.PUT(pathItem, h.ParseItem, h.Update)
-	authorized.DELETE(pathItem, h.ParseItem, h.CanDelete, h.Delete)
-	authorized.POST(pathItem, h.ParseItem, h.IsNew, h.Create)
+	authorized.POST(pathAsset, h.ParseAsset, h.AssetCreate)
 
-	authorized.PUT(pathCheck, h.ParseCheck, h.UpdateChecks)
+	authorized.PUT(pathForm, h.ParseForm, h.IsUnlocked, h.GuardStatus(engine), h.Update)
+	authorized.DELETE(pathForm, h.ParseForm, h.CanDelete, h.IsUnlocked, h.Delete)
+	authorized.POST(pathForm, h.ParseForm, h.IsNew, h.GuardStatus(engine), h.Create)
+	authorized.POST(actionPath(pathLock, pathForm), h.SetForm, h.Lock)
+	authorized.DELETE(actionPath(pathLock, pathForm), h.SetForm, h.Unlock)
+	authorized.POST(actionPath(pathStatus, pathForm), h.SetForm, h.IsUnlocked, h.Transition(engine))
 
-	authorized.POST(pathAsset, h.ParseAsset, h.AssetCreate)
+	authorized.PUT(pathTypeNode, h.SetType, h.ParseTypeNode, h.IsUnlocked, h.GuardStatus(engine), h.Update)
+	authorized.DELETE(pathTypeNode, h.SetType, h.ParseTypeNode, h.CanDeleteNode, h.IsUnlocked, h.Delete)
+	authorized.POST(pathTypeNode, h.SetType, h.ParseTypeNode, h.IsNewNode, h.GuardStatus(engine), h.Create)
+	authorized.POST(pathTypeStatus, h.SetType, h.SetTypeNode, h.IsUnlocked, h.Transition(engine))
 
-	authorized.PUT(pathForm, h.ParseForm, h.Update)
-	authorized.DELETE(pathForm, h.ParseForm, h.CanDelete, h.Delete)
-	authorized.POST(pathForm, h.ParseForm, h.IsNew, h.Create)
+	authorized.GET(pathAudit, engine.RequireRole(auth.RoleAdmin), h.Audit)
+	authorized.GET(pathStale, h.Stale)
+	authorized.GET(pathReferences, h.References)
+	authorized.GET(pathOrphans, h.Orphans)
 
 	loop(o.repo.Pull, 10*time.Minute, hookCh)
 
 	// Force first update
 	log.Println("First repo update...")
 	hookCh <- struct{}{}
+	return nil
+}
 
+// actionPath returns the path of an action on a component
+func actionPath(action, p string) string {
+	return action + strings.TrimPrefix(p, pathRepo)
 }
 
 func loop(action func(), every time.Duration, trigger <-chan struct{}) <-chan struct{} {
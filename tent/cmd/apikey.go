@@ -0,0 +1,99 @@
+package cmd
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/securityfirst/tent/auth"
+	"github.com/spf13/cobra"
+)
+
+var apikeyFlags struct {
+	Name    string
+	Scopes  []string
+	Expires time.Duration
+}
+
+// apikeyCmd respresents the apikey command
+var apikeyCmd = &cobra.Command{
+	Use:   "apikey",
+	Short: "API key commands",
+	Long:  `Commands for handling the API keys used by automations`,
+}
+
+var apikeyCreateCmd = &cobra.Command{
+	Use:   "create",
+	Short: "Creates an API key",
+	Long:  `Creates an API key and prints it, the key cannot be retrieved later.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		keys := openKeyStore()
+		var expires time.Time
+		if apikeyFlags.Expires > 0 {
+			expires = time.Now().Add(apikeyFlags.Expires)
+		}
+		secret, key, err := keys.Create(apikeyFlags.Name, apikeyFlags.Scopes, expires)
+		if err != nil {
+			log.Fatalf("Key error: %s", err)
+		}
+		log.Printf("Created key %s (%s)", key.ID, strings.Join(key.Scopes, ","))
+		fmt.Println(secret)
+	},
+}
+
+var apikeyListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "Lists API keys",
+	Run: func(cmd *cobra.Command, args []string) {
+		w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(w, "ID\tNAME\tSCOPES\tCREATED\tEXPIRES")
+		for _, k := range openKeyStore().List() {
+			expires := "never"
+			if !k.Expires.IsZero() {
+				expires = k.Expires.Format(time.RFC3339)
+			}
+			if k.Expired() {
+				expires += " (expired)"
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", k.ID, k.Name, strings.Join(k.Scopes, ","), k.Created.Format(time.RFC3339), expires)
+		}
+		w.Flush()
+	},
+}
+
+var apikeyRevokeCmd = &cobra.Command{
+	Use:   "revoke id...",
+	Short: "Revokes API keys",
+	Args:  cobra.MinimumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		keys := openKeyStore()
+		for _, id := range args {
+			if err := keys.Revoke(id); err != nil {
+				log.Fatalf("Key %s: %s", id, err)
+			}
+			log.Printf("Revoked key %s", id)
+		}
+	},
+}
+
+func openKeyStore() *auth.KeyStore {
+	if config.APIKeys == "" {
+		log.Fatal("APIKeys file not configured")
+	}
+	keys, err := auth.OpenKeyStore(config.APIKeys)
+	if err != nil {
+		log.Fatalf("Key store error: %s", err)
+	}
+	return keys
+}
+
+func init() {
+	apikeyCreateCmd.Flags().StringVar(&apikeyFlags.Name, "name", "", "name of the key")
+	apikeyCreateCmd.Flags().StringSliceVar(&apikeyFlags.Scopes, "scope", []string{auth.ScopeAll}, "allowed actions (create, update, delete or *)")
+	apikeyCreateCmd.Flags().DurationVar(&apikeyFlags.Expires, "expires", 0, "validity of the key (0 never expires)")
+	apikeyCmd.AddCommand(apikeyCreateCmd, apikeyListCmd, apikeyRevokeCmd)
+	RootCmd.AddCommand(apikeyCmd)
+}
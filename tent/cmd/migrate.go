@@ -0,0 +1,75 @@
+package cmd
+
+import (
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/securityfirst/tent/component"
+	"github.com/spf13/cobra"
+)
+
+var migrateFlags struct {
+	To string
+}
+
+// migrateCmd respresents the migrate command
+var migrateCmd = &cobra.Command{
+	Use:   "migrate dir",
+	Short: "Converts the metadata syntax",
+	Long:  `Converts the metadata of all the contents and forms in a local copy of the repository.`,
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		format, err := component.CheckFormat(migrateFlags.To)
+		if err != nil {
+			log.Fatalf("Format %q: %s", migrateFlags.To, err)
+		}
+		parser := component.Parser{Format: format}
+		root := args[0]
+		var count int
+		err = filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+			if err != nil {
+				return err
+			}
+			rel, err := filepath.Rel(root, path)
+			if err != nil {
+				return err
+			}
+			rel = filepath.ToSlash(rel)
+			if info.IsDir() {
+				if strings.HasPrefix(info.Name(), ".") && rel != "." {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+			if filepath.Ext(rel) != ".md" || !strings.HasPrefix(rel, "contents") && !strings.HasPrefix(rel, "forms") {
+				return nil
+			}
+			b, err := ioutil.ReadFile(path)
+			if err != nil {
+				return err
+			}
+			contents, err := parser.Migrate(rel, string(b))
+			if err != nil {
+				log.Printf("Skipping %s: %s", rel, err)
+				return nil
+			}
+			if contents == string(b) {
+				return nil
+			}
+			count++
+			return ioutil.WriteFile(path, []byte(contents), info.Mode())
+		})
+		if err != nil {
+			log.Fatalf("Migrate error: %s", err)
+		}
+		log.Printf("Converted %d files to %s", count, migrateFlags.To)
+	},
+}
+
+func init() {
+	RootCmd.AddCommand(migrateCmd)
+	migrateCmd.Flags().StringVar(&migrateFlags.To, "to", component.FormatYAML, "syntax of the metadata (yaml or rows)")
+}
@@ -20,6 +20,8 @@ import (
 	"os"
 
 	"github.com/securityfirst/tent/auth"
+	"github.com/securityfirst/tent/component"
+	"github.com/securityfirst/tent/logging"
 	"github.com/securityfirst/tent/repo"
 	"github.com/securityfirst/tent/transifex"
 	"github.com/spf13/cobra"
@@ -30,11 +32,14 @@ var cfgFile string
 
 var config struct {
 	Server struct {
-		Port   int
-		Prefix string
+		Port       int
+		Prefix     string
+		TrustProxy bool
 	}
 	Github struct {
 		Handler, Project, Branch string
+		App                      repo.AppConf
+		Credentials              repo.CredentialsConf
 	}
 	Transifex struct {
 		Project        transifex.Project
@@ -44,13 +49,47 @@ var config struct {
 		RequestPerHour int
 		Filter         string
 	}
-	PauseOnError bool
-	Root         string
+	Audit struct {
+		File string
+		IP   string
+		Key  string
+	}
+	Leases        repo.LeaseConf
+	Metadata      string
+	Hierarchy     component.Profile
+	Types         []component.Profile
+	GlossaryLinks bool
+	Commit        repo.CommitConf
+	Log           logging.Config
+	PauseOnError  bool
+	Root          string
 	auth.Config
 }
 
 func newRepo() (*repo.Repo, error) {
-	return repo.New(config.Github.Handler, config.Github.Project, config.Github.Branch)
+	var (
+		app   *repo.App
+		creds repo.Credentials
+		err   error
+	)
+	if config.Github.App.ID != 0 {
+		if app, err = repo.NewApp(config.Github.App, config.Github.Handler, config.Github.Project); err != nil {
+			return nil, err
+		}
+		creds = app.Credentials
+	} else if creds, err = config.Github.Credentials.Credentials(); err != nil {
+		return nil, err
+	}
+	r, err := repo.New(config.Github.Handler, config.Github.Project, config.Github.Branch, creds)
+	if err != nil {
+		return nil, err
+	}
+	r.SetApp(app)
+	if err := r.SetMetaFormat(config.Metadata); err != nil {
+		return nil, err
+	}
+	r.SetPushCredentials(config.Github.Credentials.Push)
+	return r, nil
 }
 
 var RootCmd = &cobra.Command{
@@ -92,6 +131,18 @@ func initConfig() {
 	if err := viper.Unmarshal(&config); err != nil {
 		log.Fatal("Error:", err)
 	}
+	repo.SetLogger(logging.New(os.Stdout, "repo", config.Log))
+	if _, err := component.CheckFormat(config.Metadata); err != nil {
+		log.Fatal("Error:", err)
+	}
+	for _, t := range config.Types {
+		if err := component.Register(t); err != nil {
+			log.Fatalf("Type %q: %s", t.Name, err)
+		}
+	}
+	if config.Github.App.ID != 0 && len(config.Scopes) == 0 {
+		config.Scopes = auth.IdentityScopes
+	}
 	if config.Transifex.Language == "" {
 		config.Transifex.Language = "en"
 	}
@@ -26,6 +26,9 @@ import (
 
 	"github.com/gin-gonic/gin"
 	"github.com/securityfirst/tent"
+	"github.com/securityfirst/tent/audit"
+	"github.com/securityfirst/tent/logging"
+	"github.com/securityfirst/tent/repo"
 	"github.com/spf13/cobra"
 )
 
@@ -39,7 +42,13 @@ var runCmd = &cobra.Command{
 			flag.Usage()
 			os.Exit(1)
 		}
-		e := gin.Default()
+		if logging.ParseLevel(config.Log.Level) != logging.LevelDebug {
+			gin.SetMode(gin.ReleaseMode)
+		}
+		e := gin.New()
+		e.ForwardedByClientIP = config.Server.TrustProxy
+		httpLog := logging.New(os.Stdout, "http", config.Log)
+		e.Use(httpLog.Middleware(), httpLog.Recovery())
 		srv := &http.Server{
 			Addr:    fmt.Sprintf(":%v", config.Server.Port),
 			Handler: e,
@@ -48,9 +57,33 @@ var runCmd = &cobra.Command{
 		if err != nil {
 			log.Fatalf("Repo error: %s", err)
 		}
+		if err := r.SetCommitConf(config.Commit); err != nil {
+			log.Fatalf("Commit error: %s", err)
+		}
+		if config.Audit.File != "" {
+			l, err := audit.Open(config.Audit.File, config.Audit.IP, config.Audit.Key)
+			if err != nil {
+				log.Fatalf("Audit error: %s", err)
+			}
+			defer l.Close()
+			r.SetAudit(l)
+		}
+		leases, err := repo.OpenLeases(config.Leases)
+		if err != nil {
+			log.Fatalf("Leases error: %s", err)
+		}
+		r.SetLeases(leases)
+		r.SetGlossaryLinks(config.GlossaryLinks)
+		if config.Hierarchy.Root != "" {
+			if err := r.SetProfile(config.Hierarchy); err != nil {
+				log.Fatalf("Hierarchy error: %s", err)
+			}
+		}
 
 		o := tent.New(r)
-		o.Register(e.Group(config.Server.Prefix), config.Config)
+		if err := o.Register(e.Group(config.Server.Prefix), config.Config); err != nil {
+			log.Fatalf("Auth error: %s", err)
+		}
 
 		stop := make(chan os.Signal, 1)
 		signal.Notify(stop, os.Interrupt)
@@ -0,0 +1,70 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"sort"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+var staleFlags struct {
+	Locale string
+	JSON   bool
+}
+
+// staleCmd respresents the stale command
+var staleCmd = &cobra.Command{
+	Use:   "stale",
+	Short: "Lists stale contents",
+	Long:  `Lists the contents overdue for expert review, by locale, with their last change.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		r, err := newRepo()
+		if err != nil {
+			log.Fatalf("Repo error: %s", err)
+		}
+		r.Pull()
+		stale, err := r.Stale(staleFlags.Locale, time.Now())
+		if err != nil {
+			log.Fatalf("Stale error: %s", err)
+		}
+		if staleFlags.JSON {
+			e := json.NewEncoder(os.Stdout)
+			e.SetIndent("", "\t")
+			e.Encode(stale)
+			return
+		}
+		var locales = make([]string, 0, len(stale))
+		for l := range stale {
+			locales = append(locales, l)
+		}
+		sort.Strings(locales)
+		w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(w, "LOCALE\tPATH\tDUE\tLAST CHANGE\tAUTHOR")
+		for _, l := range locales {
+			for _, e := range stale[l] {
+				due, change := "never reviewed", "n/a"
+				if e.Due != nil {
+					due = e.Due.Format(dateFormat)
+				}
+				if e.Date != nil {
+					change = e.Date.Format(dateFormat)
+				}
+				fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", l, e.Path, due, change, e.Author)
+			}
+		}
+		w.Flush()
+	},
+}
+
+const dateFormat = "2006-01-02"
+
+func init() {
+	RootCmd.AddCommand(staleCmd)
+	staleCmd.Flags().StringVar(&staleFlags.Locale, "locale", "", "locale of the contents, all if empty")
+	staleCmd.Flags().BoolVar(&staleFlags.JSON, "json", false, "prints the report as JSON")
+}
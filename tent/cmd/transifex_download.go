@@ -47,9 +47,12 @@ func downloadRun(cmd *cobra.Command, args []string) {
 	}
 
 	r, err := newRepo()
+	if err != nil {
+		log.Fatalf("Repo error: %s", err)
+	}
 	r.Pull()
 
-	quit := make(chan os.Signal)
+	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, os.Interrupt)
 
 	go func() {
@@ -138,10 +141,10 @@ func downloadDifficulties(langs []string) (map[string]map[string]string, error)
 func saveResults(parser *component.ResourceParser, difficulties map[string]map[string]string) {
 	for lang, cats := range parser.Categories() {
 		for _, cat := range cats {
-			utils.WriteCmp(config.Root, cat)
+			utils.WriteCmp(config.Root, cat, config.Metadata)
 			for _, s := range cat.Subcategories() {
 				sub := cat.Sub(s)
-				utils.WriteCmp(config.Root, sub)
+				utils.WriteCmp(config.Root, sub, config.Metadata)
 				for _, d := range sub.DifficultyNames() {
 					diff := sub.Difficulty(d)
 					if l, ok := difficulties[lang]; ok {
@@ -151,13 +154,18 @@ func saveResults(parser *component.ResourceParser, difficulties map[string]map[s
 						}
 					}
 					if check := diff.Checks(); check.HasChildren() {
-						utils.WriteCmp(config.Root, check)
+						utils.WriteCmp(config.Root, check, config.Metadata)
 					}
 					for _, i := range diff.ItemNames() {
-						utils.WriteCmp(config.Root, diff.Item(i))
+						utils.WriteCmp(config.Root, diff.Item(i), config.Metadata)
 					}
 				}
 			}
 		}
 	}
+	for _, nodes := range parser.Nodes() {
+		for _, n := range nodes {
+			utils.WriteCmp(config.Root, n, config.Metadata)
+		}
+	}
 }
@@ -7,7 +7,8 @@ import (
 	"github.com/securityfirst/tent/component"
 )
 
-func WriteCmp(base string, c component.Component) error {
+// WriteCmp writes the component in the directory, with the metadata in the format
+func WriteCmp(base string, c component.Component, format string) error {
 	path := filepath.Join(base, c.Path())
 	if err := os.MkdirAll(filepath.Dir(path), 0777); err != nil {
 		return err
@@ -16,7 +17,12 @@ func WriteCmp(base string, c component.Component) error {
 	if err != nil {
 		return err
 	}
-	if _, err := f.WriteString(c.Contents()); err != nil {
+	p := component.Parser{Format: format}
+	contents, err := p.Contents(c)
+	if err != nil {
+		return err
+	}
+	if _, err := f.WriteString(contents); err != nil {
 		return err
 	}
 	return nil